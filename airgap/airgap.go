@@ -0,0 +1,232 @@
+// Package airgap checks that the machine the tool runs on is offline before
+// any secret is handled: no network interface other than loopback is up, there
+// is no default route, and no Wi-Fi or Bluetooth radio is enabled.
+package airgap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Policy is what to do when the machine does not look air-gapped.
+type Policy string
+
+const (
+	// Refuse stops before any secret is handled.
+	Refuse Policy = "refuse"
+	// Warn prints a warning and goes on.
+	Warn Policy = "warn"
+	// Off skips the check.
+	Off Policy = "off"
+)
+
+// ParsePolicy parses a policy name.
+func ParsePolicy(name string) (Policy, error) {
+	policy := Policy(strings.ToLower(strings.TrimSpace(name)))
+	switch policy {
+	case Refuse, Warn, Off:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown air-gap policy: %s (expected refuse, warn or off)", name)
+}
+
+const (
+	// iffUp is the flag of network interfaces that are up.
+	iffUp = 0x1
+	// arphrdLoopback is the hardware type of loopback interfaces.
+	arphrdLoopback = 772
+	// rtfUp and rtfReject are the flags of usable and of blackhole routes.
+	rtfUp     = 0x1
+	rtfReject = 0x200
+)
+
+// Radio is a wireless device listed by rfkill.
+type Radio struct {
+	Name string `json:"name"`
+	// Type is the rfkill type of the device, like wlan or bluetooth
+	Type string `json:"type"`
+	// Blocked is whether the radio is turned off, by software or hardware
+	Blocked bool `json:"blocked"`
+}
+
+// Report is the result of an air-gap check.
+type Report struct {
+	// Interfaces lists the network interfaces that are up, except loopback
+	Interfaces []string `json:"interfaces"`
+	// DefaultRoutes lists the interfaces with an IPv4 or IPv6 default route
+	DefaultRoutes []string `json:"default_routes"`
+	// Radios lists the Wi-Fi and Bluetooth devices
+	Radios []Radio `json:"radios"`
+}
+
+// Problems returns why the machine does not look air-gapped, or nothing if it
+// does.
+func (r Report) Problems() []string {
+	var problems []string
+	for _, iface := range r.Interfaces {
+		problems = append(problems, fmt.Sprintf("network interface %s is up", iface))
+	}
+	for _, iface := range r.DefaultRoutes {
+		problems = append(problems, fmt.Sprintf("default route through %s", iface))
+	}
+	for _, radio := range r.Radios {
+		if !radio.Blocked {
+			problems = append(problems, fmt.Sprintf("%s radio %s is not blocked by rfkill", radio.Type, radio.Name))
+		}
+	}
+	return problems
+}
+
+// Offline reports whether the machine looks air-gapped.
+func (r Report) Offline() bool {
+	return len(r.Problems()) == 0
+}
+
+// CheckRoot inspects the network interfaces in sys/class/net, the routes in
+// proc/net and the radios in sys/class/rfkill under root, which is "/" for
+// the running system.
+func CheckRoot(root string) (Report, error) {
+	var report Report
+	var err error
+	if report.Interfaces, err = upInterfaces(filepath.Join(root, "sys/class/net")); err != nil {
+		return Report{}, fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+	if report.DefaultRoutes, err = defaultRoutes(filepath.Join(root, "proc/net")); err != nil {
+		return Report{}, fmt.Errorf("failed to read routes: %w", err)
+	}
+	if report.Radios, err = radios(filepath.Join(root, "sys/class/rfkill")); err != nil {
+		return Report{}, fmt.Errorf("failed to list radios: %w", err)
+	}
+	return report, nil
+}
+
+// upInterfaces returns the interfaces in dir that are up and not loopback.
+func upInterfaces(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var up []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if kind, err := readInt(filepath.Join(dir, name, "type"), 10); err == nil && kind == arphrdLoopback {
+			continue
+		}
+		flags, err := readInt(filepath.Join(dir, name, "flags"), 0)
+		if err != nil {
+			return nil, err
+		}
+		if flags&iffUp != 0 {
+			up = append(up, name)
+		}
+	}
+	return up, nil
+}
+
+// defaultRoutes returns the interfaces of the usable IPv4 and IPv6 default
+// routes listed in dir, which is usually /proc/net.
+func defaultRoutes(dir string) ([]string, error) {
+	var routes []string
+	add := func(iface string) {
+		if !slices.Contains(routes, iface) {
+			routes = append(routes, iface)
+		}
+	}
+
+	// Iface Destination Gateway Flags ..., with a header line
+	err := readFields(filepath.Join(dir, "route"), func(fields []string) {
+		if len(fields) < 4 || fields[1] != "00000000" {
+			return
+		}
+		if flags, err := strconv.ParseUint(fields[3], 16, 32); err == nil && flags&rtfUp != 0 && flags&rtfReject == 0 {
+			add(fields[0])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Destination PrefixLength Source SourcePrefix NextHop Metric RefCount Use Flags Iface
+	err = readFields(filepath.Join(dir, "ipv6_route"), func(fields []string) {
+		if len(fields) < 10 || fields[1] != "00" || strings.Trim(fields[0], "0") != "" {
+			return
+		}
+		if flags, err := strconv.ParseUint(fields[8], 16, 32); err == nil && flags&rtfUp != 0 && flags&rtfReject == 0 {
+			add(fields[9])
+		}
+	})
+	if err != nil && !os.IsNotExist(err) {
+		// IPv6 may be disabled
+		return nil, err
+	}
+	return routes, nil
+}
+
+// radios returns the Wi-Fi and Bluetooth devices listed in dir, which is
+// usually /sys/class/rfkill and does not exist without any radio.
+func radios(dir string) ([]Radio, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result []Radio
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		kind, err := os.ReadFile(filepath.Join(path, "type"))
+		if err != nil {
+			return nil, err
+		}
+		radio := Radio{Name: entry.Name(), Type: strings.TrimSpace(string(kind))}
+		if radio.Type != "wlan" && radio.Type != "bluetooth" {
+			continue
+		}
+		if name, err := os.ReadFile(filepath.Join(path, "name")); err == nil {
+			radio.Name = strings.TrimSpace(string(name))
+		}
+		soft, err := readInt(filepath.Join(path, "soft"), 10)
+		if err != nil {
+			return nil, err
+		}
+		hard, err := readInt(filepath.Join(path, "hard"), 10)
+		if err != nil {
+			return nil, err
+		}
+		radio.Blocked = soft != 0 || hard != 0
+		result = append(result, radio)
+	}
+	return result, nil
+}
+
+// readInt reads a file holding a single integer in the given base, or in the
+// base of its prefix if base is 0.
+func readInt(path string, base int) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), base, 64)
+}
+
+// readFields calls fn with the fields of each line of a file.
+func readFields(path string, fn func(fields []string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fn(strings.Fields(scanner.Text()))
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,94 @@
+package airgap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates the files under root, with their contents.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+const (
+	routeHeader    = "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"
+	loopbackRoutes = "00000000000000000000000000000001 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001       lo\n" +
+		"00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo\n"
+)
+
+func TestCheckRoot(t *testing.T) {
+	t.Run("offline", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"sys/class/net/lo/type":         "772\n",
+			"sys/class/net/lo/flags":        "0x9\n",
+			"sys/class/net/eth0/type":       "1\n",
+			"sys/class/net/eth0/flags":      "0x1002\n",
+			"proc/net/route":                routeHeader,
+			"proc/net/ipv6_route":           loopbackRoutes,
+			"sys/class/rfkill/rfkill0/type": "wlan\n",
+			"sys/class/rfkill/rfkill0/name": "phy0\n",
+			"sys/class/rfkill/rfkill0/soft": "1\n",
+			"sys/class/rfkill/rfkill0/hard": "0\n",
+		})
+
+		report, err := CheckRoot(root)
+		require.NoError(t, err)
+		assert.True(t, report.Offline(), report.Problems())
+		assert.Equal(t, []Radio{{Name: "phy0", Type: "wlan", Blocked: true}}, report.Radios)
+	})
+
+	t.Run("online", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"sys/class/net/lo/type":    "772\n",
+			"sys/class/net/lo/flags":   "0x9\n",
+			"sys/class/net/eth0/type":  "1\n",
+			"sys/class/net/eth0/flags": "0x1003\n",
+			"proc/net/route": routeHeader +
+				"eth0\t00000000\t010200C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n" +
+				"eth0\t000200C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+			"proc/net/ipv6_route": loopbackRoutes +
+				"00000000000000000000000000000000 00 00000000000000000000000000000000 00 fd000000000000000000000000000001 00000400 00000001 00000000 00000003     eth0\n",
+			"sys/class/rfkill/rfkill0/type": "bluetooth\n",
+			"sys/class/rfkill/rfkill0/name": "hci0\n",
+			"sys/class/rfkill/rfkill0/soft": "0\n",
+			"sys/class/rfkill/rfkill0/hard": "0\n",
+			"sys/class/rfkill/rfkill1/type": "nfc\n",
+		})
+
+		report, err := CheckRoot(root)
+		require.NoError(t, err)
+		assert.False(t, report.Offline())
+		assert.Equal(t, []string{
+			"network interface eth0 is up",
+			"default route through eth0",
+			"bluetooth radio hci0 is not blocked by rfkill",
+		}, report.Problems())
+	})
+
+	t.Run("missing_sysfs", func(t *testing.T) {
+		_, err := CheckRoot(t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list network interfaces")
+	})
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, name := range []string{"refuse", "Warn", " off "} {
+		_, err := ParsePolicy(name)
+		require.NoError(t, err, name)
+	}
+	_, err := ParsePolicy("ignore")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown air-gap policy: ignore")
+}
@@ -0,0 +1,9 @@
+//go:build linux
+
+package airgap
+
+// Check inspects the network interfaces, routes and radios of the running
+// system.
+func Check() (Report, error) {
+	return CheckRoot("/")
+}
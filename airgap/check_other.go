@@ -0,0 +1,14 @@
+//go:build !linux
+
+package airgap
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Check inspects the network interfaces, routes and radios of the running
+// system. It is only supported on Linux.
+func Check() (Report, error) {
+	return Report{}, fmt.Errorf("air-gap check not supported on %s", runtime.GOOS)
+}
@@ -2,34 +2,80 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/rand"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/tyler-smith/go-bip39"
-	"github.com/victorges/recovery-shards/command"
+	"github.com/victorges/recovery-shards/airgap"
+	"github.com/victorges/recovery-shards/entropy"
 	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/oplog"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shards"
+	"github.com/victorges/recovery-shards/tui"
+	"github.com/victorges/recovery-shards/wallet"
+	"github.com/victorges/recovery-shards/wordcode"
+	"golang.org/x/term"
 )
 
 // Version is set during build via ldflags
 var Version = "dev"
 
-func promptForPhrase(prompt string) (string, error) {
-	fmt.Println(prompt)
+// readHiddenLine reads a line of secret input. If stdin is a terminal, its echo
+// is turned off while the line is typed. The line should be wiped once used.
+func readHiddenLine(messages io.Writer, reader *bufio.Scanner) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		if !reader.Scan() {
+			return nil, fmt.Errorf("failed to read input")
+		}
+		return bytes.Clone(reader.Bytes()), nil
+	}
+
+	line, err := term.ReadPassword(fd)
+	fmt.Fprintln(messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return line, nil
+}
+
+// stdinPiped returns whether stdin is not a terminal, in which case split and
+// recover read their whole input from it at once, like from a file, instead of
+// prompting for it.
+func stdinPiped() bool {
+	return !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptForPhrase prompts for the 24 words of a phrase, one at a time, without
+// echoing them. Each word may also be written in any of the short forms
+// accepted by wordcode.Expand, with decimal indices starting at base. The words
+// returned are the entries of the wordlist.
+func promptForPhrase(messages io.Writer, prompt string, base int) ([]string, error) {
+	fmt.Fprintln(messages, prompt)
 	words := make([]string, 0, 24)
 	reader := bufio.NewScanner(os.Stdin)
 
 	for i := 0; i < 24; {
-		fmt.Printf("Word %d: ", i)
-		if !reader.Scan() {
-			return "", fmt.Errorf("failed to read input")
+		fmt.Fprintf(messages, "Word %d: ", i+1)
+		line, err := readHiddenLine(messages, reader)
+		if err != nil {
+			return nil, err
 		}
 
-		word := strings.TrimSpace(strings.ToLower(reader.Text()))
-		if _, ok := bip39.GetWordIndex(word); !ok {
-			fmt.Printf("Invalid word: %s\n", word)
+		word, err := wordcode.Expand(string(line), base)
+		secure.Wipe(line)
+		if err != nil {
+			fmt.Fprintln(messages, "Invalid word, please try again.")
 			continue
 		}
 
@@ -37,26 +83,61 @@ func promptForPhrase(prompt string) (string, error) {
 		i++
 	}
 
-	return strings.Join(words, " "), nil
+	return words, nil
+}
+
+// byteReader reads one byte at a time, so that reading a line from a pipe does
+// not consume the lines after it, which may be read by the next prompt.
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) Read(p []byte) (int, error) {
+	return r.Reader.Read(p[:min(len(p), 1)])
+}
+
+// promptForLine prompts for a line of secret input, without echoing it.
+func promptForLine(messages io.Writer, prompt string) (string, error) {
+	fmt.Fprintln(messages, prompt)
+	line, err := readHiddenLine(messages, bufio.NewScanner(byteReader{os.Stdin}))
+	if err != nil {
+		return "", err
+	}
+	defer secure.Wipe(line)
+	return string(bytes.TrimSpace(line)), nil
+}
+
+// promptForExtraEntropy prompts for extra user entropy to mix into the
+// randomness of a split, without echoing it. It should be wiped once used.
+func promptForExtraEntropy(messages io.Writer) ([]byte, error) {
+	fmt.Fprintln(messages, "Type random keys as extra entropy, then press Enter:")
+	line, err := readHiddenLine(messages, bufio.NewScanner(byteReader{os.Stdin}))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil, fmt.Errorf("no extra entropy entered")
+	}
+	return line, nil
 }
 
-func promptForShares(count int) ([]model.MnemonicShare, error) {
+func promptForShares(messages io.Writer, count, base int) ([]model.MnemonicShare, error) {
 	shares := make([]model.MnemonicShare, 0, count)
 	for i := 0; i < count; i++ {
-		fmt.Printf("\nShare %d:\n", i+1)
-		fmt.Print("Identifier (hex): ")
+		fmt.Fprintf(messages, "\nShare %d:\n", i+1)
+		fmt.Fprint(messages, "Identifier (hex): ")
 
 		var identifier string
 		if _, err := fmt.Scanln(&identifier); err != nil {
 			return nil, fmt.Errorf("failed to read identifier: %w", err)
 		}
 
-		mnemonic, err := promptForPhrase("Enter the mnemonic phrase for this share:")
+		words, err := promptForPhrase(messages, "Enter the mnemonic phrase for this share:", base)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read mnemonic: %w", err)
 		}
 
-		share, err := model.NewMnemonicShare(identifier, mnemonic)
+		share, err := model.NewMnemonicShare(identifier, strings.Join(words, " "))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create mnemonic share: %w", err)
 		}
@@ -65,252 +146,1325 @@ func promptForShares(count int) ([]model.MnemonicShare, error) {
 	return shares, nil
 }
 
-func readMnemonicLine(content string) (string, string, error) {
-	words := strings.Fields(content)
-	identifier := ""
-	if len(words) == 25 {
-		identifier = words[0]
-		words = words[1:]
-	} else if len(words) != 24 {
-		return "", "", fmt.Errorf("mnemonic must contain exactly 24 words")
+// promptForPhraseTUI shows the terminal UI to enter a mnemonic phrase, and
+// returns its words.
+func promptForPhraseTUI(messages io.Writer, base int) ([]string, error) {
+	terminal, err := tui.Open(os.Stdin, messages)
+	if err != nil {
+		return nil, err
 	}
+	defer terminal.Close()
 
-	for _, word := range words {
-		if _, ok := bip39.GetWordIndex(word); !ok {
-			return "", "", fmt.Errorf("invalid word in mnemonic: %s", word)
-		}
+	form := tui.NewForm("Enter your recovery phrase", false, 24, []int{12, 15, 18, 21, 24})
+	form.IndexBase = base
+	form.Validate = func(_ string, words []string) error {
+		entropy, err := model.PhraseEntropy(words)
+		secure.Wipe(entropy)
+		return err
 	}
-
-	return identifier, strings.Join(words, " "), nil
+	_, words, err := terminal.Run(form)
+	return words, err
 }
 
-func readMnemonicFromFile(filepath string) (string, string, error) {
-	content, err := os.ReadFile(filepath)
+// promptForSharesTUI shows the terminal UI to enter count shares, one after
+// the other, with the progress of the ones already entered.
+func promptForSharesTUI(messages io.Writer, count, base int) ([]model.MnemonicShare, error) {
+	terminal, err := tui.Open(os.Stdin, messages)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read mnemonic file: %w", err)
+		return nil, err
 	}
+	defer terminal.Close()
 
-	return readMnemonicLine(string(content))
+	shares := make([]model.MnemonicShare, 0, count)
+	entered := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		form := tui.NewForm(fmt.Sprintf("Enter share %d of %d", i+1, count), true, 24, []int{12, 15, 18, 21, 24, 36, 60})
+		form.Header = tui.Progress(entered, count)
+		form.IndexBase = base
+		form.Validate = func(identifier string, words []string) error {
+			share, err := model.NewMnemonicShare(identifier, strings.Join(words, " "))
+			if err != nil {
+				return err
+			}
+			for _, other := range shares {
+				if bytes.Equal(other.Identifier, share.Identifier) {
+					return fmt.Errorf("share 0x%04x was already entered", share.Identifier)
+				}
+			}
+			return nil
+		}
+
+		identifier, words, err := terminal.Run(form)
+		if err != nil {
+			return nil, err
+		}
+		share, err := model.NewMnemonicShare(identifier, strings.Join(words, " "))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mnemonic share: %w", err)
+		}
+		shares = append(shares, share)
+		entered = append(entered, fmt.Sprintf("0x%04x", share.Identifier))
+	}
+	return shares, nil
 }
 
-func readSharesFromFile(filepath string) ([]model.MnemonicShare, error) {
-	content, err := os.ReadFile(filepath)
+func readSecretFromFile(path string, seedType seed.Type, base int) (seed.Secret, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read share file: %w", err)
+		return seed.Secret{}, fmt.Errorf("failed to read mnemonic file: %w", err)
 	}
+	defer file.Close()
+	return shards.ReadSecret(file, seedType, base)
+}
 
-	shares := make([]model.MnemonicShare, 0)
-	for _, line := range strings.Split(string(content), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// readManifest reads the manifest at path. If path is empty, the manifest
+// found next to the shares is read if there is one, and nil is returned
+// otherwise. Copies of the manifest may be found next to several copies of the
+// shares, but they must all be of the same share set.
+func readManifest(path string, found []string) (*model.Manifest, error) {
+	if path != "" {
+		return shards.ReadManifest(path)
+	}
+	var manifest *model.Manifest
+	for _, file := range found {
+		other, err := shards.ReadManifest(file)
+		if err != nil {
+			return nil, err
+		}
+		if manifest == nil {
+			manifest = other
+		} else if other.SetID != manifest.SetID {
+			return nil, fmt.Errorf("found manifests of different share sets in %s and %s, choose one with -manifest", found[0], file)
 		}
+	}
+	return manifest, nil
+}
 
-		identifier, mnemonic, err := readMnemonicLine(line)
-		if err != nil {
-			return nil, fmt.Errorf("invalid mnemonic line: %w", err)
+// printSources prints the files each share was found in.
+func printSources(messages io.Writer, found []shards.FoundShare) {
+	for _, share := range found {
+		fmt.Fprintf(messages, "Found share 0x%04x in %s\n", share.Share.Identifier, strings.Join(share.Files, ", "))
+	}
+}
+
+// shareSources returns the files each share was found in, by hex identifier.
+func shareSources(found []shards.FoundShare) map[string][]string {
+	sources := make(map[string][]string, len(found))
+	for _, share := range found {
+		sources[fmt.Sprintf("%04x", share.Share.Identifier)] = share.Files
+	}
+	return sources
+}
+
+// saveShares saves the shares and their manifest to outputPath, and returns the
+// files written.
+func saveShares(messages io.Writer, split *shards.Split, outputPath string) (files []string, manifestFile string, err error) {
+	if files, err = shards.WriteShares(split.Shares, outputPath); err != nil {
+		return nil, "", err
+	}
+	if len(files) == len(split.Shares) {
+		for i, file := range files {
+			fmt.Fprintf(messages, "Saved share %d to %s\n", i+1, file)
 		}
-		if identifier == "" {
-			return nil, fmt.Errorf("share file must contain identifiers")
+	} else {
+		fmt.Fprintf(messages, "Saved %d shares to %s\n", len(split.Shares), outputPath)
+	}
+
+	if manifestFile, err = shards.WriteManifest(split.Manifest, outputPath); err != nil {
+		return nil, "", err
+	}
+	fmt.Fprintf(messages, "Saved manifest of share set %s to %s\n", split.Manifest.SetID, manifestFile)
+	return files, manifestFile, nil
+}
+
+// printShares prints the shares to w with their words written in the given
+// format, with decimal indices starting at base.
+func printShares(messages, w io.Writer, shares []model.MnemonicShare, format wordcode.Format, base int) error {
+	fmt.Fprintln(messages, "Shares:")
+	encoder := shards.NewEncoder(w)
+	encoder.Format, encoder.IndexBase = format, base
+	for _, share := range shares {
+		if err := encoder.Encode(share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmTranscriptions asks the operator to retype each share from its
+// written copy, and repeats a share until its identifier and every word match.
+// The words may be written in any of the short forms accepted by
+// wordcode.Expand, with decimal indices starting at base.
+func confirmTranscriptions(messages io.Writer, shares []model.MnemonicShare, base int, reader *bufio.Scanner) error {
+	fmt.Fprintln(messages, "\nRetype each share from its written copy to check it was copied correctly.")
+	for _, share := range shares {
+		for confirmed := false; !confirmed; {
+			fmt.Fprintf(messages, "\n%s, identifier (hex): ", share.Name())
+			if !reader.Scan() {
+				return fmt.Errorf("failed to read input: %s was not confirmed", share.Name())
+			}
+			identifier := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(reader.Text())), "0x")
+			idMatches := identifier == fmt.Sprintf("%04x", share.Identifier)
+
+			fmt.Fprintf(messages, "%s, words on one line: ", share.Name())
+			line, err := readHiddenLine(messages, reader)
+			if err != nil {
+				return err
+			}
+			tokens := strings.Fields(string(line))
+			secure.Wipe(line)
+			words := make([]string, len(tokens))
+			for i, token := range tokens {
+				// a word that cannot be expanded is kept as typed, so it is
+				// reported as wrong
+				words[i] = token
+				if word, err := wordcode.Expand(token, base); err == nil {
+					words[i] = word
+				}
+			}
+
+			wrong := share.WrongWords(words)
+			if !idMatches {
+				fmt.Fprintf(messages, "The identifier does not match 0x%04x.\n", share.Identifier)
+			}
+			if len(wrong) > 0 {
+				positions := make([]string, len(wrong))
+				for i, position := range wrong {
+					positions[i] = strconv.Itoa(position)
+				}
+				if len(positions) == 1 {
+					fmt.Fprintf(messages, "Wrong word at position %s.\n", positions[0])
+				} else {
+					fmt.Fprintf(messages, "Wrong words at positions %s.\n", strings.Join(positions, ", "))
+				}
+			}
+			if confirmed = idMatches && len(wrong) == 0; confirmed {
+				fmt.Fprintf(messages, "%s confirmed.\n", share.Name())
+			} else {
+				fmt.Fprintln(messages, "Check the written copy and retype the share.")
+			}
 		}
+	}
+	fmt.Fprintln(messages, "\nAll shares were copied correctly.")
+	return nil
+}
+
+// pathsFlag is a flag that may be given several times, like -in.
+type pathsFlag []string
+
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *pathsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// parseLabels parses the comma-separated custodian labels of n shares.
+func parseLabels(value string, n int) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
 
-		share, err := model.NewMnemonicShare(identifier, mnemonic)
+	labels := strings.Split(value, ",")
+	if len(labels) != n {
+		return nil, fmt.Errorf("expected %d labels, one for each share, got %d", n, len(labels))
+	}
+	for i, label := range labels {
+		labels[i] = strings.TrimSpace(label)
+		if labels[i] == "" {
+			return nil, fmt.Errorf("empty label for share %d", i+1)
+		}
+		if slices.Contains(labels[:i], labels[i]) {
+			return nil, fmt.Errorf("duplicate label: %s", labels[i])
+		}
+	}
+	return labels, nil
+}
+
+// custodians returns which custodians contributed shares to a recovery and, if
+// the whole set is known from the manifest, which ones are missing.
+func custodians(shares []model.MnemonicShare, manifest *model.Manifest) (present, missing []string) {
+	if manifest != nil {
+		return manifest.Custodians(shares)
+	}
+	present = make([]string, len(shares))
+	for i, share := range shares {
+		present[i] = share.Name()
+	}
+	return present, nil
+}
+
+// printCustodians prints the custodians returned by custodians.
+func printCustodians(messages io.Writer, present, missing []string) {
+	fmt.Fprintf(messages, "Shares contributed by: %s\n", strings.Join(present, ", "))
+	if len(missing) > 0 {
+		fmt.Fprintf(messages, "Shares still missing: %s\n", strings.Join(missing, ", "))
+	}
+}
+
+// secretDetails holds public information derived from a recovered secret, which
+// can be used to check that the right secret was recovered.
+type secretDetails struct {
+	ExtendedPublicKey   string `json:"extended_public_key,omitempty"`
+	Address             string `json:"address,omitempty"`
+	ElectrumSeedVersion string `json:"electrum_seed_version,omitempty"`
+}
+
+// describeSecret returns the details of the secret that depend on its type.
+func describeSecret(secret seed.Secret) (secretDetails, error) {
+	var details secretDetails
+	switch secret.Type {
+	case seed.XPRV:
+		key, err := wallet.DecodeExtendedKey(secret.Data)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create mnemonic share: %w", err)
+			return details, err
 		}
-		shares = append(shares, share)
+		details.ExtendedPublicKey = key.Neuter().String()
+	case seed.WIF:
+		wif, err := wallet.DecodeWIF(secret.Data)
+		if err != nil {
+			return details, err
+		}
+		details.Address = wif.Address()
+	case seed.Electrum:
+		phrase, err := secret.Text()
+		if err != nil {
+			return details, err
+		}
+		details.ElectrumSeedVersion = seed.ElectrumSeedVersion(phrase)
 	}
-	return shares, nil
+	return details, nil
 }
 
-func readSharesFromDirectory(directory string) ([]model.MnemonicShare, error) {
-	files, err := os.ReadDir(directory)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+// printSecretDetails prints the details of the secret that are set.
+func printSecretDetails(messages io.Writer, details secretDetails) {
+	switch {
+	case details.ExtendedPublicKey != "":
+		fmt.Fprintf(messages, "\nExtended public key: %s\n", details.ExtendedPublicKey)
+	case details.Address != "":
+		fmt.Fprintf(messages, "\nAddress: %s\n", details.Address)
+	case details.ElectrumSeedVersion != "":
+		fmt.Fprintf(messages, "\nElectrum seed version: %s\n", details.ElectrumSeedVersion)
 	}
+}
 
-	allShares := make([]model.MnemonicShare, 0, len(files))
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+// secretOutput is where the recover command writes the recovered secret.
+type secretOutput struct {
+	// wipe is whether the secret is displayed on the alternate screen and
+	// cleared once a key is pressed
+	wipe bool
+	// path is the file the secret is written to, if not empty
+	path string
+	// fd is the file descriptor the secret is written to, if not negative
+	fd int
+}
+
+// parseSecretOutput checks the output options of the recover command, of which
+// only one can be used at a time.
+func parseSecretOutput(wipe bool, path string, fd int) (secretOutput, error) {
+	output := secretOutput{wipe: wipe, path: path, fd: fd}
+	options := 0
+	for _, set := range []bool{wipe, path != "", fd >= 0} {
+		if set {
+			options++
 		}
+	}
+	if options > 1 {
+		return secretOutput{}, fmt.Errorf("only one of -wipe, -out-file and -out-fd can be used")
+	}
+	if fd == int(os.Stdout.Fd()) || fd == int(os.Stderr.Fd()) {
+		return secretOutput{}, fmt.Errorf("-out-fd must not be stdout or stderr")
+	}
+	return output, nil
+}
+
+// stdout returns whether the secret is printed to stdout, which is the default.
+func (o secretOutput) stdout() bool {
+	return !o.wipe && o.path == "" && o.fd < 0
+}
 
-		shares, err := readSharesFromFile(filepath.Join(directory, file.Name()))
+// write outputs the recovered secret to the chosen output. By default, it is
+// printed to stdout.
+func (o secretOutput) write(stdout, messages io.Writer, secret seed.Secret) error {
+	description := secret.Type.Description()
+	switch {
+	case o.wipe:
+		return tui.Show(os.Stdin, messages, fmt.Sprintf("Recovered %s:", description), secret.WriteText)
+	case o.path != "":
+		// remove any existing file, so that its permissions are not kept
+		if err := os.Remove(o.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to replace output file: %w", err)
+		}
+		file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 		if err != nil {
-			return nil, fmt.Errorf("error reading %s: %w", file.Name(), err)
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		if err := writeSecretLine(file, secret); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
 		}
-		allShares = append(allShares, shares...)
+		fmt.Fprintf(messages, "Recovered %s written to %s.\n", description, o.path)
+		return file.Close()
+	case o.fd >= 0:
+		file := os.NewFile(uintptr(o.fd), "output")
+		if file == nil {
+			return fmt.Errorf("invalid file descriptor: %d", o.fd)
+		}
+		defer file.Close()
+		if err := writeSecretLine(file, secret); err != nil {
+			return fmt.Errorf("failed to write to file descriptor %d: %w", o.fd, err)
+		}
+		fmt.Fprintf(messages, "Recovered %s written to file descriptor %d.\n", description, o.fd)
+		return nil
 	}
-	return allShares, nil
+
+	fmt.Fprintf(messages, "Recovered %s:\n\n", description)
+	return writeSecretLine(stdout, secret)
 }
 
-func readSharesFromPath(path string) ([]model.MnemonicShare, error) {
-	fileInfo, err := os.Stat(path)
+// writeSecretLine writes the text form of the secret to w, followed by a new
+// line.
+func writeSecretLine(w io.Writer, secret seed.Secret) error {
+	if err := secret.WriteText(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// showFields lists the wallet information that can be requested with the
+// -show flag of the recover command.
+var showFields = []string{"fingerprint", "xpub", "address"}
+
+func parseShowFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	fields := make([]string, 0, len(showFields))
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(showFields, field) {
+			return nil, fmt.Errorf("unknown -show field: %s (expected %s)", field, strings.Join(showFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// walletInfo holds the public wallet information derived from a secret.
+type walletInfo struct {
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Path is the derivation path of the account
+	Path             string `json:"path,omitempty"`
+	AccountPublicKey string `json:"account_public_key,omitempty"`
+	// ReceiveAddress is the first receive address of the account, at
+	// <path>/0/0
+	ReceiveAddress string `json:"receive_address,omitempty"`
+}
+
+// deriveWalletInfo derives the requested wallet information from the master
+// key of the secret. It returns nil for an empty field list.
+func deriveWalletInfo(secret seed.Secret, fields []string, purpose wallet.Purpose) (*walletInfo, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	master, err := secret.MasterKey()
+	if err != nil {
+		return nil, err
+	}
+	account, err := wallet.DeriveAccount(master, purpose, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read path: %w", err)
+		return nil, err
 	}
 
-	if fileInfo.IsDir() {
-		return readSharesFromDirectory(path)
+	info := &walletInfo{}
+	for _, field := range fields {
+		switch field {
+		case "fingerprint":
+			info.Fingerprint = fmt.Sprintf("%x", master.Fingerprint())
+		case "xpub":
+			info.Path = wallet.FormatPath(account.Path)
+			info.AccountPublicKey = account.PublicKey
+		case "address":
+			info.Path = wallet.FormatPath(account.Path)
+			info.ReceiveAddress = account.ReceiveAddress
+		}
 	}
-	return readSharesFromFile(path)
+	return info, nil
 }
 
-func writeShares(shares []model.MnemonicShare, outputPath string) error {
-	// Check if path ends with slash or is an existing directory
-	isDir := strings.HasSuffix(outputPath, "/") || strings.HasSuffix(outputPath, "\\")
-	if !isDir {
-		if info, err := os.Stat(outputPath); err == nil {
-			isDir = info.IsDir()
+// printWalletInfo prints the wallet information that is set. Nothing is
+// printed for nil.
+func printWalletInfo(messages io.Writer, info *walletInfo) {
+	if info == nil {
+		return
+	}
+	fmt.Fprintln(messages)
+	if info.Fingerprint != "" {
+		fmt.Fprintf(messages, "Master fingerprint: %s\n", info.Fingerprint)
+	}
+	if info.AccountPublicKey != "" {
+		fmt.Fprintf(messages, "Account public key (%s): %s\n", info.Path, info.AccountPublicKey)
+	}
+	if info.ReceiveAddress != "" {
+		fmt.Fprintf(messages, "First receive address (%s/0/0): %s\n", info.Path, info.ReceiveAddress)
+	}
+}
+
+// generateEntropy returns the entropy for a new mnemonic with the given number
+// of words, either from the OS RNG or from the user input of the source, which
+// is prompted for without echo or read from stdin if it is piped.
+func generateEntropy(messages io.Writer, words int, source string, mix bool) ([]byte, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return nil, fmt.Errorf("invalid number of words: %d (expected 12, 15, 18, 21 or 24)", words)
+	}
+	size := words * 4 / 3
+	if source == "" {
+		if mix {
+			return nil, fmt.Errorf("-mix requires -entropy-source")
 		}
+		return bip39.NewEntropy(size * 8)
 	}
 
-	if isDir {
-		// Ensure directory exists
-		dirPath := strings.TrimRight(outputPath, "/\\")
-		if err := os.MkdirAll(dirPath, 0700); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+	src, err := entropy.ParseSource(source)
+	if err != nil {
+		return nil, err
+	}
+	required := (&entropy.UserEntropy{Source: src}).RequiredSymbols(size)
+	prompt := fmt.Sprintf("Enter at least %d %s symbols on a single line:", required, src)
+	input, err := promptForLine(messages, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := entropy.Read(src, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range user.Warnings {
+		fmt.Fprintf(messages, "Warning: %s\n", warning)
+	}
+
+	data, err := user.Entropy(size)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(messages, "Using %.0f bits of entropy from %d %s symbols.\n", user.Bits, len(user.Symbols), src)
+	if mix {
+		defer clear(data)
+		return entropy.Mix(data, rand.Reader)
+	}
+	return data, nil
+}
+
+// printAudit prints how the bits of the entropy and checksum map to words.
+func printAudit(messages io.Writer, data []byte) error {
+	audit, err := entropy.Audit(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(messages, "\n  #  Bits          Index  Word")
+	for _, w := range audit {
+		bits := w.Bits
+		if w.ChecksumBits > 0 {
+			// separate the checksum bits from the entropy bits
+			bits = bits[:len(bits)-w.ChecksumBits] + "|" + bits[len(bits)-w.ChecksumBits:]
 		}
+		fmt.Fprintf(messages, "%3d  %-12s  %5d  %s\n", w.Position, bits, w.Index, w.Word)
+	}
+	fmt.Fprintf(messages, "\nThe bits after | in the last word are the checksum: the first %d bits of the SHA-256 of the entropy.\n", audit[len(audit)-1].ChecksumBits)
+	return nil
+}
 
-		// Write individual files
-		for i, share := range shares {
-			filename := filepath.Join(dirPath, fmt.Sprintf("share_%04x.txt", share.Identifier))
+func printFinalWords(messages io.Writer, words []string) {
+	fmt.Fprintf(messages, "%d possible last words with a valid checksum:\n\n", len(words))
+	fmt.Fprintln(messages, strings.Join(words, " "))
+}
 
-			if err := os.WriteFile(filename, []byte(share.String()), 0600); err != nil {
-				return fmt.Errorf("failed to write share file: %w", err)
-			}
-			fmt.Printf("Saved share %d to %s\n", i+1, filename)
+// splitOptions holds the options shared by split and generate -split.
+type splitOptions struct {
+	// total and threshold are the n and k of the split
+	total, threshold int
+	// outputPath is where the shares and manifest are saved, if not empty
+	outputPath string
+	// extraEntropy is mixed into the randomness of the split, if not empty
+	extraEntropy []byte
+	// labels holds the custodian label of each share, if any
+	labels []string
+	// format is how the words of the printed shares are written, with
+	// decimal indices starting at indexBase
+	format    wordcode.Format
+	indexBase int
+	// confirm is whether the operator must retype each share from its written
+	// copy before the split is done
+	confirm bool
+	// json is whether the result is output as a JSON document, instead of
+	// printing the shares
+	json bool
+	// pipe is whether the shares are printed alone to stdout, with all the
+	// other messages on stderr
+	pipe bool
+	// log is the operation log the split is recorded in
+	log *oplog.Log
+}
+
+// splitRecord is the operation log entry of a split.
+type splitRecord struct {
+	SecretType  seed.Type `json:"secret_type"`
+	Total       int       `json:"total"`
+	Threshold   int       `json:"threshold"`
+	Shares      []string  `json:"shares"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	SetID       string    `json:"set_id,omitempty"`
+	Output      string    `json:"output,omitempty"`
+}
+
+// recoverRecord is the operation log entry of a recovery.
+type recoverRecord struct {
+	SecretType seed.Type `json:"secret_type"`
+	Shares     []string  `json:"shares"`
+	// SetID is the share set of the manifest the shares were verified
+	// against, if any
+	SetID string `json:"set_id,omitempty"`
+}
+
+// shareIDs returns the hex identifiers of the shares.
+func shareIDs(shares []model.MnemonicShare) []string {
+	ids := make([]string, len(shares))
+	for i, share := range shares {
+		ids[i] = fmt.Sprintf("%04x", share.Identifier)
+	}
+	return ids
+}
+
+// splitAndSave splits the secret into shares, verifies them and then saves
+// them along with their manifest, if an output path is given, and prints them.
+// It returns the result of the split for the JSON output.
+func splitAndSave(stdout, messages io.Writer, secret seed.Secret, opts splitOptions) (*splitResult, error) {
+	n, k := opts.total, opts.threshold
+	splitter, err := shards.NewSplitter(shards.SplitOptions{
+		Total:        n,
+		Threshold:    k,
+		ExtraEntropy: opts.extraEntropy,
+		Labels:       opts.labels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error: %w", err)
+	}
+	split, err := splitter.Split(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w", err)
+	}
+	shares := split.Shares
+
+	if secret.Type != seed.BIP39 {
+		fmt.Fprintf(messages, "Splitting %s.\n", secret.Type.Description())
+	}
+
+	fmt.Fprintf(messages, "Generated %d shares with a %d-out-of-%d threshold.\n", n, k, n)
+	result := &splitResult{SecretType: secret.Type, Total: n, Threshold: k, Shares: shares}
+	if split.Manifest.Fingerprint != "" {
+		fmt.Fprintf(messages, "Master fingerprint: %s\n", split.Manifest.Fingerprint)
+		result.Fingerprint = split.Manifest.Fingerprint
+	}
+
+	if opts.outputPath != "" {
+		if result.Files, result.Manifest, err = saveShares(messages, split, opts.outputPath); err != nil {
+			return nil, fmt.Errorf("error: %w", err)
 		}
-	} else {
-		// Write single file with all shares
-		var content strings.Builder
-		for _, share := range shares {
-			fmt.Fprintln(&content, share)
+		result.SetID = split.Manifest.SetID
+	}
+	record := splitRecord{
+		SecretType:  secret.Type,
+		Total:       n,
+		Threshold:   k,
+		Shares:      shareIDs(shares),
+		Output:      opts.outputPath,
+		Fingerprint: result.Fingerprint,
+		SetID:       result.SetID,
+	}
+	if err := opts.log.Record("split", record); err != nil {
+		return nil, fmt.Errorf("error: %w", err)
+	}
+	// with the JSON output, the shares are only written in the JSON document
+	if !opts.json {
+		w := messages
+		if opts.pipe {
+			w = stdout
+		}
+		if err := printShares(messages, w, shares, opts.format, opts.indexBase); err != nil {
+			return nil, fmt.Errorf("error: %w", err)
 		}
+	}
+	if opts.confirm {
+		if err := confirmTranscriptions(messages, shares, opts.indexBase, bufio.NewScanner(os.Stdin)); err != nil {
+			return nil, fmt.Errorf("error: %w", err)
+		}
+		if err := opts.log.Record("confirm", map[string]any{"shares": shareIDs(shares)}); err != nil {
+			return nil, fmt.Errorf("error: %w", err)
+		}
+	}
+	return result, nil
+}
 
-		if err := os.WriteFile(outputPath, []byte(content.String()), 0600); err != nil {
-			return fmt.Errorf("failed to write shares file: %w", err)
+// safetyFlags holds the flags of the checks and hardening done before any
+// secret is read, which are shared by the commands that handle secrets.
+type safetyFlags struct {
+	hardening *secure.Options
+	airgap    *string
+	logPath   *string
+}
+
+// addSafetyFlags adds the safety flags to fs. Each hardening step is enabled by
+// default, and has a flag to opt out of it.
+func addSafetyFlags(fs *flag.FlagSet) safetyFlags {
+	opts := secure.DefaultOptions()
+	fs.BoolVar(&opts.LockMemory, "mlock", opts.LockMemory, "Lock the process memory with mlockall so secrets are not swapped to disk (disable with -mlock=false)")
+	fs.BoolVar(&opts.NoDump, "no-dump", opts.NoDump, "Mark the process as not dumpable with PR_SET_DUMPABLE=0 (disable with -no-dump=false)")
+	fs.BoolVar(&opts.NoCore, "no-core", opts.NoCore, "Disable core dumps of the process (disable with -no-core=false)")
+	fs.BoolVar(&opts.SwapWarning, "swap-warning", opts.SwapWarning, "Warn at startup if swap is active (disable with -swap-warning=false)")
+	return safetyFlags{
+		hardening: &opts,
+		airgap:    fs.String("airgap", "warn", "What to do if the machine does not look air-gapped: refuse, warn or off"),
+		logPath:   fs.String("log", "", "Append a record of the operation, without any secret, to this operation log file"),
+	}
+}
+
+// harden hardens the process as set by the safety flags. Tests replace it, so
+// that the test binary itself is never locked in memory or made undumpable.
+var harden = secure.Harden
+
+// prepare hardens the process and checks that the machine is air-gapped,
+// before any secret is read, and returns the operation log of command.
+func (f safetyFlags) prepare(messages io.Writer, command string) (*oplog.Log, error) {
+	policy, err := airgap.ParsePolicy(*f.airgap)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, warning := range harden(*f.hardening) {
+		fmt.Fprintf(messages, "Warning: %s\n", warning)
+	}
+
+	log := oplog.New(*f.logPath, command)
+	if err := checkAirgap(messages, policy, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// airgapCheck is the operation log entry of an air-gap check.
+type airgapCheck struct {
+	Policy airgap.Policy `json:"policy"`
+	// Result is offline, warned, refused, skipped or error
+	Result   string         `json:"result"`
+	Problems []string       `json:"problems,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Report   *airgap.Report `json:"report,omitempty"`
+}
+
+// checkAirgap checks that the machine is air-gapped and warns or refuses to go
+// on if it is not, depending on the policy. The result is recorded in log.
+func checkAirgap(messages io.Writer, policy airgap.Policy, log *oplog.Log) error {
+	check := airgapCheck{Policy: policy}
+	var refusal error
+	if policy == airgap.Off {
+		check.Result = "skipped"
+	} else if report, err := airgap.Check(); err != nil {
+		check.Result, check.Error = "error", err.Error()
+		if policy == airgap.Refuse {
+			refusal = fmt.Errorf("could not check that the machine is air-gapped: %w", err)
+		} else {
+			fmt.Fprintf(messages, "Warning: could not check that the machine is air-gapped: %v\n", err)
+		}
+	} else {
+		check.Report, check.Problems = &report, report.Problems()
+		switch {
+		case len(check.Problems) == 0:
+			check.Result = "offline"
+		case policy == airgap.Refuse:
+			check.Result = "refused"
+			refusal = fmt.Errorf("the machine is not air-gapped: %s", strings.Join(check.Problems, ", "))
+		default:
+			check.Result = "warned"
+			for _, problem := range check.Problems {
+				fmt.Fprintf(messages, "Warning: the machine is not air-gapped, %s\n", problem)
+			}
 		}
-		fmt.Printf("Saved %d shares to %s\n", len(shares), outputPath)
 	}
-	return nil
+
+	if err := log.Record("airgap_check", check); err != nil {
+		return err
+	}
+	return refusal
 }
 
-func printShares(shares []model.MnemonicShare) {
-	fmt.Println("Shares:")
-	for _, share := range shares {
-		fmt.Println(share)
+// parseCommand parses the flags of a command, along with its -output format.
+// The flags after one that cannot be parsed are left unset, so -output is then
+// looked for in args, for the usage error to be written in the JSON document
+// too.
+func parseCommand(fs *flag.FlagSet, args []string, format *string, stdout, stderr io.Writer) (*commandOutput, error) {
+	parseErr := fs.Parse(args)
+	if errors.Is(parseErr, flag.ErrHelp) {
+		return nil, parseErr
+	}
+	if parseErr != nil {
+		*format = outputFlag(args, *format)
+	}
+	output, err := newCommandOutput(fs.Name(), *format, stdout, stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w", err)
+	}
+	if parseErr != nil {
+		return output, usageError(parseErr.Error())
+	}
+	return output, nil
+}
+
+// outputFlag returns the value of the -output flag in args, or value if it is
+// not there.
+func outputFlag(args []string, value string) string {
+	for i, arg := range args {
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, flagValue, found := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case name != "output":
+		case found:
+			value = flagValue
+		case i+1 < len(args):
+			value = args[i+1]
+		}
 	}
+	return value
 }
 
 func RunCLI(args []string) error {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+// run runs the command line args, with the output of the commands written to
+// stdout and stderr.
+func run(args []string, stdout, stderr io.Writer) (err error) {
+	// messages is where the human-readable messages of the command are
+	// printed: stdout, or stderr when stdout only holds a JSON document, the
+	// shares or the secret
+	messages := stdout
+
 	// Check for version flag
 	if len(args) > 1 && (args[1] == "-v" || args[1] == "--version" || args[1] == "version") {
-		fmt.Printf("recovery-shards version %s\n", Version)
+		fmt.Fprintf(messages, "recovery-shards version %s\n", Version)
 		return nil
 	}
 
-	splitCmd := flag.NewFlagSet("split", flag.ExitOnError)
+	splitCmd := flag.NewFlagSet("split", flag.ContinueOnError)
+	splitSafety := addSafetyFlags(splitCmd)
 	splitTotal := splitCmd.Int("n", 3, "Total number of shares to create (default: 3)")
 	splitThreshold := splitCmd.Int("k", 2, "Minimum number of shares needed to recover the phrase (default: 2)")
-	splitInputFile := splitCmd.String("in", "", "File containing the recovery phrase (if not provided, will prompt for input)")
+	splitInputFile := splitCmd.String("in", "", "File containing the recovery phrase, xprv/tprv or WIF key (if not provided, read from stdin if it is piped, or prompted for)")
 	splitOutputDir := splitCmd.String("out", "", "Directory to save the generated shares")
+	splitExtraEntropy := splitCmd.Bool("extra-entropy", false, "Prompt for extra user entropy, like random keystrokes, to mix into the randomness used to split (read from stdin if it is piped)")
+	splitLabels := splitCmd.String("labels", "", "Comma-separated custodian labels, one for each share, like \"Alice,Bob,Bank box\"")
+	splitWordFormat := splitCmd.String("word-format", "words", "How the words of the printed shares are written: words, prefix, index, binary or dots")
+	splitIndexBase := splitCmd.Int("index-base", 1, "First decimal word index, 1 or 0, for input and for -word-format index")
+	splitConfirm := splitCmd.Bool("confirm", false, "After printing the shares, retype each one from its written copy to check it was copied correctly")
+	splitTUI := splitCmd.Bool("tui", false, "Enter the recovery phrase in a full-screen terminal UI")
+	splitSeedType := splitCmd.String("seed-type", "auto", "Type of the secret to split: auto, bip39, electrum, monero, aezeed, xprv or wif")
+	splitOutputFormat := splitCmd.String("output", "text", "Output format: text, or json to write the result as a JSON document to stdout")
+
+	generateCmd := flag.NewFlagSet("generate", flag.ContinueOnError)
+	generateSafety := addSafetyFlags(generateCmd)
+	generateSplit := generateCmd.Bool("split", false, "Split the generated mnemonic into shares instead of displaying it")
+	generateTotal := generateCmd.Int("n", 3, "Total number of shares to create with -split (default: 3)")
+	generateThreshold := generateCmd.Int("k", 2, "Minimum number of shares needed to recover the phrase with -split (default: 2)")
+	generateOutputDir := generateCmd.String("out", "", "Directory to save the generated shares with -split")
+	generateWords := generateCmd.Int("words", 24, "Number of words of the generated mnemonic: 12, 15, 18, 21 or 24")
+	generateSource := generateCmd.String("entropy-source", "", "Use entropy supplied by the user instead of the OS RNG: dice, coins or hex")
+	generateMix := generateCmd.Bool("mix", false, "Mix the user-supplied entropy with the OS RNG by hashing them together")
+	generateAudit := generateCmd.Bool("audit", false, "Show how the entropy bits map to each word of the generated mnemonic")
+	generateFinalWord := generateCmd.Bool("final-word", false, "Prompt for a phrase of 11, 14, 17, 20 or 23 chosen words, or read it from stdin if it is piped, and calculate its possible last words")
+	generateExtraEntropy := generateCmd.Bool("extra-entropy", false, "Prompt for extra user entropy, like random keystrokes, to mix into the randomness used to split with -split (read from stdin if it is piped)")
+	generateLabels := generateCmd.String("labels", "", "Comma-separated custodian labels, one for each share, with -split")
+	generateWordFormat := generateCmd.String("word-format", "words", "How the words of the printed shares are written with -split: words, prefix, index, binary or dots")
+	generateIndexBase := generateCmd.Int("index-base", 1, "First decimal word index, 1 or 0, for -word-format index")
+	generateConfirm := generateCmd.Bool("confirm", false, "After printing the shares with -split, retype each one from its written copy to check it was copied correctly")
+	generatePath := generateCmd.String("path", "bip84", "Derivation path of the account public key and address shown with -split: bip44, bip49, bip84 or bip86")
+	generateOutputFormat := generateCmd.String("output", "text", "Output format: text, or json to write the result as a JSON document to stdout")
 
-	recoverCmd := flag.NewFlagSet("recover", flag.ExitOnError)
+	recoverCmd := flag.NewFlagSet("recover", flag.ContinueOnError)
+	recoverSafety := addSafetyFlags(recoverCmd)
 	recoverShareCount := recoverCmd.Int("shares", 0, "Number of shares to input manually")
-	recoverInputDir := recoverCmd.String("in", "", "Path to a directory containing share files")
+	var recoverInputs pathsFlag
+	recoverCmd.Var(&recoverInputs, "in", "Share file or directory, or a glob pattern like \"/media/*/shares\", which may be repeated; any path after the options is read too (if not provided, the shares are read from stdin if it is piped)")
+	recoverRecursive := recoverCmd.Bool("recursive", false, "Also read the share files in the subdirectories of the -in directories")
+	recoverManifest := recoverCmd.String("manifest", "", "Path to the manifest written by split (default: the manifest next to the shares in -in, if any)")
+	recoverShow := recoverCmd.String("show", "", "Comma-separated wallet information to derive from the recovered secret: fingerprint, xpub, address")
+	recoverPath := recoverCmd.String("path", "bip84", "Derivation path of the account for -show: bip44, bip49, bip84 or bip86")
+	recoverTUI := recoverCmd.Bool("tui", false, "Enter the shares in a full-screen terminal UI (the number of shares is -shares, or the threshold of -manifest)")
+	recoverWipe := recoverCmd.Bool("wipe", false, "Display the recovered secret until a key is pressed, then clear the screen and scrollback")
+	recoverOutputFile := recoverCmd.String("out-file", "", "Write the recovered secret only to this file, created with 0600 permissions, instead of stdout")
+	recoverOutputFD := recoverCmd.Int("out-fd", -1, "Write the recovered secret only to this open file descriptor, instead of stdout")
+	recoverIndexBase := recoverCmd.Int("index-base", 1, "First decimal word index, 1 or 0, of shares written as word indices")
+	recoverOutputFormat := recoverCmd.String("output", "text", "Output format: text, or json to write the result as a JSON document to stdout")
+
+	inspectCmd := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	inspectManifest := inspectCmd.String("manifest", "", "Path to the manifest written by split (default: the manifest next to the share file, if any)")
+	inspectIndexBase := inspectCmd.Int("index-base", 1, "First decimal word index, 1 or 0, of shares written as word indices")
+	inspectOutputFormat := inspectCmd.String("output", "text", "Output format: text, or json to write the result as a JSON document to stdout")
+
+	doctorCmd := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	var doctorInputs pathsFlag
+	doctorCmd.Var(&doctorInputs, "in", "Share file or directory to check, or a glob pattern like \"/media/*/shares\", which may be repeated; any path after the options is checked too")
+	doctorRecursive := doctorCmd.Bool("recursive", false, "Also check the files in the subdirectories of the -in directories")
+	doctorManifest := doctorCmd.String("manifest", "", "Path to the manifest written by split (default: the manifests next to the shares in -in, if any)")
+	doctorIndexBase := doctorCmd.Int("index-base", 1, "First decimal word index, 1 or 0, of shares written as word indices")
+	doctorOutputFormat := doctorCmd.String("output", "text", "Output format: text, or json to write the result as a JSON document to stdout")
+
+	commands := []*flag.FlagSet{generateCmd, splitCmd, recoverCmd, inspectCmd, doctorCmd}
+	names := make([]string, 0, len(commands))
+	for _, fs := range commands {
+		fs.SetOutput(stderr)
+		names = append(names, "'"+fs.Name()+"'")
+	}
 
 	if len(args) < 2 {
-		return fmt.Errorf("expected 'split', 'recover', or 'version' subcommand")
+		return usageError(fmt.Sprintf("expected %s, or 'version' subcommand", strings.Join(names, ", ")))
 	}
 
+	// output is set once the flags of the command are parsed, and writes its
+	// JSON document with -output json once the command is done
+	var output *commandOutput
+	defer func() {
+		if output != nil {
+			err = output.finish(err)
+		}
+		// the usage was printed for -help
+		if errors.Is(err, flag.ErrHelp) {
+			err = nil
+		}
+	}()
+
 	switch args[1] {
 	case "generate":
-		// generate a random mnemonic. just a helpful command used for testing
-		entropy, err := bip39.NewEntropy(256)
+		if output, err = parseCommand(generateCmd, args[2:], generateOutputFormat, stdout, stderr); err != nil {
+			return err
+		}
+		messages = output.messages
+		log, err := generateSafety.prepare(messages, "generate")
 		if err != nil {
-			return fmt.Errorf("error: %v", err)
+			return fmt.Errorf("error: %w", err)
 		}
+		if *generateFinalWord {
+			phrase, err := promptForLine(messages, "Enter the 11, 14, 17, 20 or 23 chosen words on a single line:")
+			if err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			words, err := entropy.FinalWords(phrase)
+			if err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			output.result = generateResult{FinalWords: words}
+			if !output.json() {
+				printFinalWords(messages, words)
+			}
+			return nil
+		}
+		if *generateAudit && *generateSplit {
+			return fmt.Errorf("error: -audit displays the mnemonic and cannot be used with -split")
+		}
+
+		data, err := generateEntropy(messages, *generateWords, *generateSource, *generateMix)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		source := *generateSource
+		if source == "" {
+			source = "os"
+		}
+		if err := log.Record("generate", map[string]any{"words": *generateWords, "entropy_source": source, "split": *generateSplit}); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+
+		if !*generateSplit {
+			// print the mnemonic. just a helpful command used for testing
+			mnemonic, err := bip39.NewMnemonic(data)
+			if err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+
+			if output.json() {
+				result := generateResult{Mnemonic: mnemonic}
+				if *generateAudit {
+					if result.Audit, err = entropy.Audit(data); err != nil {
+						return fmt.Errorf("error: %w", err)
+					}
+				}
+				output.result = result
+				return nil
+			}
 
-		mnemonic, err := bip39.NewMnemonic(entropy)
+			fmt.Fprintln(messages, "Generated mnemonic:")
+			fmt.Fprintf(messages, "\n%s\n", mnemonic)
+			if *generateAudit {
+				if err := printAudit(messages, data); err != nil {
+					return fmt.Errorf("error: %w", err)
+				}
+			}
+			return nil
+		}
+
+		// split the new mnemonic right away, so it is never displayed
+		purpose, err := wallet.ParsePurpose(*generatePath)
 		if err != nil {
-			return fmt.Errorf("error: %v", err)
+			return fmt.Errorf("error: %w", err)
 		}
+		secret := seed.Secret{Type: seed.BIP39, Data: data}
+		defer clear(data)
 
-		fmt.Println("Generated mnemonic:")
-		fmt.Printf("\n%s\n", mnemonic)
+		fmt.Fprintln(messages, "Generated a new mnemonic and split it without displaying it.")
+		labels, err := parseLabels(*generateLabels, *generateTotal)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		format, err := wordcode.ParseFormat(*generateWordFormat)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		opts := splitOptions{
+			total:      *generateTotal,
+			threshold:  *generateThreshold,
+			outputPath: *generateOutputDir,
+			labels:     labels,
+			format:     format,
+			indexBase:  *generateIndexBase,
+			confirm:    *generateConfirm,
+			json:       output.json(),
+			log:        log,
+		}
+		if *generateExtraEntropy {
+			if opts.extraEntropy, err = promptForExtraEntropy(messages); err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			defer secure.Wipe(opts.extraEntropy)
+		}
+		result, err := splitAndSave(stdout, messages, secret, opts)
+		if err != nil {
+			return err
+		}
+		if result.Wallet, err = deriveWalletInfo(secret, []string{"xpub", "address"}, purpose); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		printWalletInfo(messages, result.Wallet)
+		output.result = result
 
 	case "split":
-		splitCmd.Parse(args[2:])
-		var mnemonic string
-		var err error
+		if output, err = parseCommand(splitCmd, args[2:], splitOutputFormat, stdout, stderr); err != nil {
+			return err
+		}
+		messages = output.messages
+		// with a piped secret, only the shares are written to stdout
+		pipe := *splitInputFile == "" && !*splitTUI && stdinPiped()
+		if pipe {
+			if *splitConfirm {
+				return fmt.Errorf("error: -confirm cannot be used with a secret piped to stdin")
+			}
+			if *splitExtraEntropy {
+				return fmt.Errorf("error: -extra-entropy cannot be used with a secret piped to stdin, use -in")
+			}
+			messages = stderr
+		}
+		log, err := splitSafety.prepare(messages, "split")
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		seedType, err := seed.ParseType(*splitSeedType)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		labels, err := parseLabels(*splitLabels, *splitTotal)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		format, err := wordcode.ParseFormat(*splitWordFormat)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
 
+		var secret seed.Secret
 		if *splitInputFile != "" {
-			var identifier string
-			identifier, mnemonic, err = readMnemonicFromFile(*splitInputFile)
+			secret, err = readSecretFromFile(*splitInputFile, seedType, *splitIndexBase)
+			if err != nil {
+				return fmt.Errorf("error reading input file: %w", err)
+			}
+		} else if pipe {
+			secret, err = shards.ReadSecret(os.Stdin, seedType, *splitIndexBase)
+			if err != nil {
+				return fmt.Errorf("error reading stdin: %w", err)
+			}
+		} else if seedType == seed.Auto || seedType == seed.BIP39 {
+			var words []string
+			if *splitTUI {
+				words, err = promptForPhraseTUI(messages, *splitIndexBase)
+			} else {
+				words, err = promptForPhrase(messages, "Enter your 24-word recovery phrase, one word at a time:", *splitIndexBase)
+			}
+			if err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			data, err := model.PhraseEntropy(words)
 			if err != nil {
-				return fmt.Errorf("error reading input file: %v", err)
-			} else if identifier != "" {
-				return fmt.Errorf("unexpected identifier in mnemonic file: %04x", identifier)
+				return fmt.Errorf("error: invalid mnemonic phrase: %w", err)
 			}
+			secret = seed.Secret{Type: seed.BIP39, Data: data}
 		} else {
-			mnemonic, err = promptForPhrase("Enter your 24-word recovery phrase, one word at a time:")
+			text, err := promptForLine(messages, fmt.Sprintf("Enter your %s:", seedType.Description()))
 			if err != nil {
-				return fmt.Errorf("error: %v", err)
+				return fmt.Errorf("error: %w", err)
+			}
+			if secret, err = seed.Parse(seedType, text); err != nil {
+				return fmt.Errorf("error: %w", err)
 			}
 		}
 
-		shares, err := command.Split(mnemonic, *splitTotal, *splitThreshold)
-		if err != nil {
-			return fmt.Errorf("error: %v", err)
-		}
+		defer secret.Wipe()
 
-		if err := command.VerifyShares(mnemonic, shares, *splitThreshold); err != nil {
-			return fmt.Errorf("error verifying shares: %v", err)
+		opts := splitOptions{
+			total:      *splitTotal,
+			threshold:  *splitThreshold,
+			outputPath: *splitOutputDir,
+			labels:     labels,
+			format:     format,
+			indexBase:  *splitIndexBase,
+			confirm:    *splitConfirm,
+			json:       output.json(),
+			pipe:       pipe,
+			log:        log,
 		}
-
-		fmt.Printf("Generated %d shares with a %d-out-of-%d threshold.\n", *splitTotal, *splitThreshold, *splitTotal)
-
-		if *splitOutputDir != "" {
-			if err := writeShares(shares, *splitOutputDir); err != nil {
-				return fmt.Errorf("error: %v", err)
+		if *splitExtraEntropy {
+			if opts.extraEntropy, err = promptForExtraEntropy(messages); err != nil {
+				return fmt.Errorf("error: %w", err)
 			}
+			defer secure.Wipe(opts.extraEntropy)
 		}
-		printShares(shares)
+		result, err := splitAndSave(stdout, messages, secret, opts)
+		if err != nil {
+			return err
+		}
+		output.result = result
 
 	case "recover":
-		recoverCmd.Parse(args[2:])
+		if output, err = parseCommand(recoverCmd, args[2:], recoverOutputFormat, stdout, stderr); err != nil {
+			return err
+		}
+		messages = output.messages
+		// with piped shares, only the secret is written to stdout
+		inputs := append(slices.Clone(recoverInputs), recoverCmd.Args()...)
+		pipe := len(inputs) == 0 && !*recoverTUI && *recoverShareCount == 0 && stdinPiped()
+		if pipe {
+			messages = stderr
+		}
+		log, err := recoverSafety.prepare(messages, "recover")
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		show, err := parseShowFields(*recoverShow)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		destination, err := parseSecretOutput(*recoverWipe, *recoverOutputFile, *recoverOutputFD)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		purpose, err := wallet.ParsePurpose(*recoverPath)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+
 		var shares []model.MnemonicShare
-		var err error
+		// found holds the shares read from files, with where each one was found
+		var found *shards.Found
 
-		if *recoverInputDir != "" {
-			shares, err = readSharesFromPath(*recoverInputDir)
+		if len(inputs) > 0 {
+			reader := shards.NewFileReader()
+			reader.IndexBase = *recoverIndexBase
+			reader.Recursive = *recoverRecursive
+			reader.Skipped = func(path string, err error) {
+				fmt.Fprintf(messages, "Warning: skipped %s: %v\n", path, err)
+			}
+			if found, err = reader.Find(inputs); err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			shares = found.MnemonicShares()
+			printSources(messages, found.Shares)
+		} else if *recoverTUI {
+			count := *recoverShareCount
+			if count == 0 && *recoverManifest != "" {
+				manifest, err := readManifest(*recoverManifest, nil)
+				if err != nil {
+					return fmt.Errorf("error: %w", err)
+				}
+				count = manifest.Threshold
+			}
+			if count == 0 {
+				return fmt.Errorf("either --shares or --manifest must be provided to recover with --tui")
+			}
+			shares, err = promptForSharesTUI(messages, count, *recoverIndexBase)
 			if err != nil {
-				return fmt.Errorf("error: %v", err)
+				return fmt.Errorf("error: %w", err)
+			}
+		} else if pipe {
+			decoder := shards.NewDecoder(os.Stdin)
+			decoder.IndexBase = *recoverIndexBase
+			if shares, err = decoder.Decode(); err != nil {
+				return fmt.Errorf("error reading stdin: %w", err)
 			}
 		} else if *recoverShareCount > 0 {
-			shares, err = promptForShares(*recoverShareCount)
+			shares, err = promptForShares(messages, *recoverShareCount, *recoverIndexBase)
 			if err != nil {
-				return fmt.Errorf("error: %v", err)
+				return fmt.Errorf("error: %w", err)
 			}
 		} else {
 			return fmt.Errorf("either --shares or --in must be provided to recover shares")
 		}
 
-		if len(shares) < 2 {
-			return fmt.Errorf("at least two shares are required to recover the mnemonic")
+		var manifests []string
+		if found != nil {
+			manifests = found.Manifests
+		}
+		manifest, err := readManifest(*recoverManifest, manifests)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
 		}
 
-		mnemonic, err := command.Recover(shares)
+		secret, err := shards.NewRecoverer(shards.RecoverOptions{Manifest: manifest}).Recover(shares)
 		if err != nil {
-			return fmt.Errorf("error: %v", err)
+			return fmt.Errorf("error: %w", err)
 		}
+		defer secret.Wipe()
 
-		fmt.Println("Recovered mnemonic phrase:")
-		fmt.Printf("\n%s\n", mnemonic)
+		record := recoverRecord{SecretType: secret.Type, Shares: shareIDs(shares)}
+		if manifest != nil {
+			record.SetID = manifest.SetID
+		}
+		if err := log.Record("recover", record); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+
+		if manifest != nil {
+			fmt.Fprintf(messages, "Shares verified against the manifest of share set %s.\n", manifest.SetID)
+		} else {
+			fmt.Fprintln(messages, "No manifest found, the recovered secret could not be verified.")
+		}
+		result := recoverResult{SecretType: secret.Type, Shares: shareIDs(shares), Verified: manifest != nil}
+		if found != nil {
+			result.Sources = shareSources(found.Shares)
+		}
+		if manifest != nil {
+			result.SetID = manifest.SetID
+		}
+		result.Custodians, result.MissingCustodians = custodians(shares, manifest)
+		printCustodians(messages, result.Custodians, result.MissingCustodians)
+
+		if output.json() && destination.stdout() {
+			// the secret is only written in the JSON document
+			if result.Secret, err = secret.Text(); err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+		} else if err := destination.write(stdout, messages, secret); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		result.OutputFile = destination.path
+		if destination.fd >= 0 {
+			result.OutputFD = &destination.fd
+		}
+
+		if result.secretDetails, err = describeSecret(secret); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		printSecretDetails(messages, result.secretDetails)
+		if result.Wallet, err = deriveWalletInfo(secret, show, purpose); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		printWalletInfo(messages, result.Wallet)
+		output.result = result
+
+	case "inspect":
+		if output, err = parseCommand(inspectCmd, args[2:], inspectOutputFormat, stdout, stderr); err != nil {
+			return err
+		}
+		messages = output.messages
+		shares, manifestPath, err := readInspectedShares(inspectCmd.Args(), *inspectIndexBase)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		if *inspectManifest != "" {
+			manifestPath = *inspectManifest
+		}
+		var manifest *model.Manifest
+		if manifestPath != "" {
+			if manifest, err = shards.ReadManifest(manifestPath); err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+		}
+
+		result := inspectResult{Shares: make([]shareInfo, 0, len(shares))}
+		for i, share := range shares {
+			info, err := inspectShare(share, manifest, manifestPath)
+			if err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+			if !output.json() {
+				if i > 0 {
+					fmt.Fprintln(messages)
+				}
+				printShareInfo(messages, share, info)
+			}
+			result.Shares = append(result.Shares, info)
+		}
+		output.result = result
+		for _, info := range result.Shares {
+			if !info.BIP39Checksum || !info.ShareChecksum {
+				return fmt.Errorf("error: share 0x%s: %w", info.Identifier, errInvalidShare)
+			}
+		}
+
+	case "doctor":
+		if output, err = parseCommand(doctorCmd, args[2:], doctorOutputFormat, stdout, stderr); err != nil {
+			return err
+		}
+		messages = output.messages
+		paths := append(doctorInputs, doctorCmd.Args()...)
+		if len(paths) == 0 {
+			return usageError("expected a share directory or share files to check, like -in shares/")
+		}
+		opts := shards.AuditOptions{IndexBase: *doctorIndexBase, Recursive: *doctorRecursive}
+		if *doctorManifest != "" {
+			if opts.Manifest, err = shards.ReadManifest(*doctorManifest); err != nil {
+				return fmt.Errorf("error: %w", err)
+			}
+		}
+		report, err := shards.Audit(paths, opts)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+
+		result := newDoctorResult(report)
+		if !output.json() {
+			printDoctorReport(messages, result)
+		}
+		output.result = result
+		if !result.Go {
+			return fmt.Errorf("error: %w", errNoGo)
+		}
 
 	default:
-		return fmt.Errorf("unknown command: %s", args[1])
+		return usageError("unknown command: " + args[1])
 	}
 
 	return nil
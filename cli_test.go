@@ -1,15 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/command"
 	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/shards"
+	"github.com/victorges/recovery-shards/wordcode"
+	"golang.org/x/term"
 )
 
+func TestMain(m *testing.M) {
+	// the commands must not lock the memory of the test process, or stop it
+	// from being debugged and dumping core
+	harden = func(secure.Options) []string { return nil }
+	os.Exit(m.Run())
+}
+
 func TestCLIEndToEnd(t *testing.T) {
 	// Generate a test mnemonic
 	entropy, err := bip39.NewEntropy(256)
@@ -148,7 +166,7 @@ func TestInvalidInputs(t *testing.T) {
 			"-in", tmpFile,
 		})
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "mnemonic must contain exactly 24 words")
+		require.Contains(t, err.Error(), "mnemonic must contain 12, 15, 18, 21 or 24 words")
 	})
 
 	t.Run("invalid_share_count", func(t *testing.T) {
@@ -188,6 +206,1082 @@ func TestInvalidInputs(t *testing.T) {
 			"-in", sharesFile,
 		})
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "at least two shares are required")
+		var insufficient *model.ErrInsufficientShares
+		require.ErrorAs(t, err, &insufficient)
+		require.Equal(t, 1, insufficient.Have)
+		require.Equal(t, exitInsufficientShares, exitCode(err))
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	dir := t.TempDir()
+	shares := []string{
+		"0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery",
+		"0xf606: ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please",
+		"0x5954: ankle salad deposit junior arrest raw box place cradle brand force boat weird involve claw neck paper vast riot prize embrace rough pelican eight",
+	}
+	writeShares := func(name string, lines ...string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600))
+		return path
+	}
+
+	testCases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{
+			name: "no_command",
+			code: exitUsage,
+		},
+		{
+			name: "unknown_command",
+			args: []string{"combine"},
+			code: exitUsage,
+		},
+		{
+			name: "invalid_checksum",
+			args: []string{"recover", "-in", writeShares("checksum.txt", shares[0], strings.Replace(shares[1], "0xf606", "0xf607", 1))},
+			code: exitInvalidShare,
+		},
+		{
+			name: "invalid_word",
+			args: []string{"recover", "-in", writeShares("word.txt", shares[0], strings.Replace(shares[1], "ride", "rade", 1))},
+			code: exitInvalidShare,
+		},
+		{
+			name: "insufficient_shares",
+			args: []string{"recover", "-in", writeShares("one.txt", shares[0])},
+			code: exitInsufficientShares,
+		},
+		{
+			// 0xadbb is a share of another split with the same number as 0xade1
+			name: "same_number",
+			args: []string{"recover", "-in", writeShares("number.txt", shares[0], "0xadbb: book wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute mercy", shares[1])},
+			code: exitMixedSets,
+		},
+		{
+			name: "valid",
+			args: []string{"recover", "-in", writeShares("valid.txt", shares[:2]...), "-out-file", filepath.Join(dir, "secret.txt")},
+			code: exitOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := RunCLI(append([]string{"recovery-shards"}, tc.args...))
+			require.Equal(t, tc.code, exitCode(err), "error: %v", err)
+		})
+	}
+
+	t.Run("mixed_sets", func(t *testing.T) {
+		first, err := model.ParseMnemonicShare(shares[0])
+		require.NoError(t, err)
+		first.Total = 3
+		second, err := model.ParseMnemonicShare(shares[1])
+		require.NoError(t, err)
+		second.Total = 5
+		_, err = command.RecoverSecret([]model.MnemonicShare{first, second})
+		require.Equal(t, exitMixedSets, exitCode(fmt.Errorf("error: %w", err)))
+	})
+}
+
+func TestCLISecretTypes(t *testing.T) {
+	testCases := []struct {
+		name, seedType, secret string
+	}{
+		{
+			name:     "xprv",
+			seedType: "auto",
+			secret:   "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+		},
+		{
+			name:     "wif",
+			seedType: "auto",
+			secret:   "5J1F7GHadZG3sCCKHCwg8Jvys9xUbFsjLnGec4H125Ny1V9nR6V",
+		},
+		{
+			name:     "electrum",
+			seedType: "electrum",
+			secret:   "wild father tree among universe such mobile favorite target dynamic credit identify",
+		},
+		{
+			name:     "monero",
+			seedType: "monero",
+			secret:   "velvet lymph giddy number token physics poetry unquoted nibs useful sabotage limits benches lifestyle eden nitrogen anvil fewest avoid batch vials washing fences goat unquoted",
+		},
+		{
+			name:     "aezeed",
+			seedType: "aezeed",
+			secret:   "ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testDir := t.TempDir()
+			secretFile := filepath.Join(testDir, "secret.txt")
+			err := os.WriteFile(secretFile, []byte(tc.secret+"\n"), 0600)
+			require.NoError(t, err)
+
+			sharesDir := filepath.Join(testDir, "shares")
+			err = RunCLI([]string{
+				"recovery-shards",
+				"split",
+				"-n", "3",
+				"-k", "2",
+				"-in", secretFile,
+				"-out", sharesDir + "/",
+				"-seed-type", tc.seedType,
+			})
+			require.NoError(t, err)
+
+			shares, err := shards.ReadShares(sharesDir, 1)
+			require.NoError(t, err)
+			require.Len(t, shares, 3)
+
+			recovered, err := command.Recover(shares[1:])
+			require.NoError(t, err)
+			require.Equal(t, tc.secret, recovered)
+
+			err = RunCLI([]string{
+				"recovery-shards",
+				"recover",
+				"-in", sharesDir,
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCLIShowWalletInfo(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	err := os.WriteFile(mnemonicFile, []byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"), 0600)
+	require.NoError(t, err)
+
+	sharesDir := filepath.Join(testDir, "shares")
+	err = RunCLI([]string{
+		"recovery-shards",
+		"split",
+		"-n", "3",
+		"-k", "2",
+		"-in", mnemonicFile,
+		"-out", sharesDir + "/",
+	})
+	require.NoError(t, err)
+
+	for _, path := range []string{"bip44", "bip49", "bip84", "bip86"} {
+		t.Run(path, func(t *testing.T) {
+			err := RunCLI([]string{
+				"recovery-shards",
+				"recover",
+				"-in", sharesDir,
+				"-show", "fingerprint,xpub,address",
+				"-path", path,
+			})
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown_field", func(t *testing.T) {
+		err := RunCLI([]string{
+			"recovery-shards",
+			"recover",
+			"-in", sharesDir,
+			"-show", "fingerprint,seed",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown -show field: seed")
+	})
+
+	t.Run("unknown_path", func(t *testing.T) {
+		err := RunCLI([]string{
+			"recovery-shards",
+			"recover",
+			"-in", sharesDir,
+			"-show", "xpub",
+			"-path", "bip32",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown derivation path")
+	})
+}
+
+func TestParseShowFields(t *testing.T) {
+	fields, err := parseShowFields(" Fingerprint, xpub ,address")
+	require.NoError(t, err)
+	require.Equal(t, []string{"fingerprint", "xpub", "address"}, fields)
+
+	fields, err = parseShowFields("")
+	require.NoError(t, err)
+	require.Empty(t, fields)
+}
+
+func TestCLIManifest(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	err := os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600)
+	require.NoError(t, err)
+
+	split := func(out string) {
+		err := RunCLI([]string{
+			"recovery-shards",
+			"split",
+			"-n", "3",
+			"-k", "2",
+			"-in", mnemonicFile,
+			"-out", out,
+		})
+		require.NoError(t, err)
+	}
+	dirA, dirB := filepath.Join(testDir, "a"), filepath.Join(testDir, "b")
+	split(dirA + "/")
+	split(dirB + "/")
+	split(filepath.Join(testDir, "shares.txt"))
+
+	manifest, err := shards.FindManifest(dirA)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	require.Equal(t, 3, manifest.Total)
+	require.Equal(t, 2, manifest.Threshold)
+
+	manifest, err = shards.FindManifest(filepath.Join(testDir, "shares.txt"))
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+
+	t.Run("valid_shares", func(t *testing.T) {
+		err := RunCLI([]string{"recovery-shards", "recover", "-in", dirA})
+		require.NoError(t, err)
+		err = RunCLI([]string{"recovery-shards", "recover", "-in", filepath.Join(testDir, "shares.txt")})
+		require.NoError(t, err)
+	})
+
+	t.Run("shares_from_different_splits", func(t *testing.T) {
+		entriesA, err := os.ReadDir(dirA)
+		require.NoError(t, err)
+		entriesB, err := os.ReadDir(dirB)
+		require.NoError(t, err)
+
+		// Replace one of the shares of the first split with one of the second
+		var removed, added bool
+		for _, entry := range entriesA {
+			if !removed && !shards.IsManifestFile(entry.Name()) {
+				require.NoError(t, os.Remove(filepath.Join(dirA, entry.Name())))
+				removed = true
+			}
+		}
+		for _, entry := range entriesB {
+			if !added && !shards.IsManifestFile(entry.Name()) {
+				content, err := os.ReadFile(filepath.Join(dirB, entry.Name()))
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(filepath.Join(dirA, "other_"+entry.Name()), content, 0600))
+				added = true
+			}
+		}
+
+		err = RunCLI([]string{"recovery-shards", "recover", "-in", dirA})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not part of share set")
+	})
+
+	t.Run("explicit_manifest", func(t *testing.T) {
+		err := RunCLI([]string{
+			"recovery-shards",
+			"recover",
+			"-in", dirB,
+			"-manifest", filepath.Join(testDir, "shares.manifest.json"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not part of share set")
+	})
+}
+
+func TestCLIGenerateSplit(t *testing.T) {
+	sharesDir := filepath.Join(t.TempDir(), "shares")
+	err := RunCLI([]string{
+		"recovery-shards",
+		"generate",
+		"-split",
+		"-n", "5",
+		"-k", "3",
+		"-out", sharesDir + "/",
+		"-path", "bip86",
+	})
+	require.NoError(t, err)
+
+	shares, err := shards.ReadShares(sharesDir, 1)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+	require.FileExists(t, filepath.Join(sharesDir, "share_3_of_5.txt"))
+	require.Equal(t, 3, shares[2].Number())
+	require.Equal(t, 5, shares[2].Total)
+
+	manifest, err := shards.FindManifest(sharesDir)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	require.Equal(t, "bip39", manifest.SecretType)
+
+	secret, err := command.RecoverWithManifest(manifest, shares[2:])
+	require.NoError(t, err)
+	master, err := secret.MasterKey()
+	require.NoError(t, err)
+	require.Equal(t, manifest.Fingerprint, fmt.Sprintf("%x", master.Fingerprint()))
+}
+
+func TestCLIGenerateUserEntropy(t *testing.T) {
+	t.Run("hex_with_audit", func(t *testing.T) {
+		pipeStdin(t, "000102030405060708090a0b0c0d0e0f\n")
+		err := RunCLI([]string{
+			"recovery-shards",
+			"generate",
+			"-entropy-source", "hex",
+			"-words", "12",
+			"-audit",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("dice_mixed_split", func(t *testing.T) {
+		sharesDir := filepath.Join(t.TempDir(), "shares")
+		pipeStdin(t, strings.Repeat("6152433465", 10)+"\n")
+		err := RunCLI([]string{
+			"recovery-shards",
+			"generate",
+			"-split",
+			"-entropy-source", "dice",
+			"-mix",
+			"-out", sharesDir + "/",
+		})
+		require.NoError(t, err)
+
+		shares, err := shards.ReadShares(sharesDir, 1)
+		require.NoError(t, err)
+		require.Len(t, shares, 3)
+	})
+
+	t.Run("final_word", func(t *testing.T) {
+		pipeStdin(t, strings.Repeat("zoo ", 11)+"\n")
+		err := RunCLI([]string{"recovery-shards", "generate", "-final-word"})
+		require.NoError(t, err)
+	})
+
+	errorCases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		errMsg string
+	}{
+		{
+			name:   "not_enough_dice",
+			args:   []string{"-entropy-source", "dice"},
+			stdin:  "123456\n",
+			errMsg: "not enough entropy",
+		},
+		{
+			name:   "audit_with_split",
+			args:   []string{"-split", "-audit"},
+			errMsg: "-audit displays the mnemonic and cannot be used with -split",
+		},
+		{
+			name:   "mix_without_source",
+			args:   []string{"-mix"},
+			errMsg: "-mix requires -entropy-source",
+		},
+		{
+			name:   "invalid_words",
+			args:   []string{"-words", "13"},
+			errMsg: "invalid number of words: 13",
+		},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.stdin != "" {
+				pipeStdin(t, tc.stdin)
+			}
+			err := RunCLI(append([]string{"recovery-shards", "generate"}, tc.args...))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+// pipeStdin makes content the stdin of the test, as if it was piped.
+func pipeStdin(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		file.Close()
+	})
+}
+
+func TestCLIExtraEntropy(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	err := os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600)
+	require.NoError(t, err)
+
+	sharesDir := filepath.Join(testDir, "shares")
+	pipeStdin(t, "qwpeoiruapsdlkfjzxcmvn\n")
+	err = RunCLI([]string{
+		"recovery-shards",
+		"split",
+		"-in", mnemonicFile,
+		"-out", sharesDir + "/",
+		"-extra-entropy",
+	})
+	require.NoError(t, err)
+
+	err = RunCLI([]string{"recovery-shards", "recover", "-in", sharesDir})
+	require.NoError(t, err)
+
+	t.Run("after_entropy_input", func(t *testing.T) {
+		// the dice rolls and the extra entropy are read from one line each
+		pipeStdin(t, strings.Repeat("3", 99)+"\nqwpeoiruapsdlkfjzxcmvn\n")
+		sharesDir := filepath.Join(t.TempDir(), "shares")
+		err := RunCLI([]string{"recovery-shards", "generate", "-split", "-entropy-source", "dice", "-extra-entropy", "-out", sharesDir + "/"})
+		require.NoError(t, err)
+	})
+
+	t.Run("piped_secret", func(t *testing.T) {
+		pipeStdin(t, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry\n")
+		err := RunCLI([]string{"recovery-shards", "split", "-extra-entropy"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "-extra-entropy cannot be used with a secret piped to stdin")
 	})
 }
+
+func TestCLIMixedShareFormats(t *testing.T) {
+	// A share in the legacy format with a random identifier and one in the
+	// numbered format, from the same split
+	sharesFile := filepath.Join(t.TempDir(), "shares.txt")
+	err := os.WriteFile(sharesFile, []byte(`0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery
+Share 246 of 255 (0xf606): ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please
+`), 0600)
+	require.NoError(t, err)
+
+	shares, err := shards.ReadShares(sharesFile, 1)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	require.Equal(t, 0, shares[0].Total)
+	require.Equal(t, 255, shares[1].Total)
+
+	mnemonic, err := command.Recover(shares)
+	require.NoError(t, err)
+	require.Equal(t, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry", mnemonic)
+}
+
+func TestCLICustodianLabels(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	err := os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600)
+	require.NoError(t, err)
+
+	sharesDir := filepath.Join(testDir, "shares")
+	err = RunCLI([]string{
+		"recovery-shards",
+		"split",
+		"-n", "5",
+		"-k", "3",
+		"-in", mnemonicFile,
+		"-out", sharesDir + "/",
+		"-labels", "Alice, Bob,Bank box,Lawyer,Safe",
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(sharesDir, "share_3_of_5_bank_box.txt"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(content), "# Custodian: Bank box\nShare 3 of 5 "))
+
+	manifest, err := shards.FindManifest(sharesDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice", "Bob", "Bank box", "Lawyer", "Safe"}, manifest.Labels)
+
+	require.NoError(t, os.Remove(filepath.Join(sharesDir, "share_2_of_5_bob.txt")))
+	require.NoError(t, os.Remove(filepath.Join(sharesDir, "share_4_of_5_lawyer.txt")))
+	shares, err := shards.ReadShares(sharesDir, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Bank box", shares[1].Label)
+
+	present, missing := manifest.Custodians(shares)
+	require.Equal(t, []string{"Alice", "Bank box", "Safe"}, present)
+	require.Equal(t, []string{"Bob", "Lawyer"}, missing)
+
+	err = RunCLI([]string{"recovery-shards", "recover", "-in", sharesDir})
+	require.NoError(t, err)
+
+	t.Run("wrong_label_count", func(t *testing.T) {
+		err := RunCLI([]string{
+			"recovery-shards",
+			"split",
+			"-n", "3",
+			"-k", "2",
+			"-in", mnemonicFile,
+			"-labels", "Alice,Bob",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected 3 labels, one for each share, got 2")
+	})
+}
+
+func TestCLIStampedShares(t *testing.T) {
+	// The shares of TestCLIMixedShareFormats, with their words written as
+	// prefixes and as 0-based word indices
+	legacy := "0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"
+	numbered := "Share 246 of 255 (0xf606): ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please"
+
+	prefixes, err := wordcode.EncodePhrase(strings.SplitN(legacy, " ", 2)[1], wordcode.Prefix, 0)
+	require.NoError(t, err)
+	indices, err := wordcode.EncodePhrase(strings.SplitN(numbered, ": ", 2)[1], wordcode.Index, 0)
+	require.NoError(t, err)
+
+	sharesFile := filepath.Join(t.TempDir(), "shares.txt")
+	err = os.WriteFile(sharesFile, []byte("0xade1: "+prefixes+"\nShare 246 of 255 (0xf606): "+indices+"\n"), 0600)
+	require.NoError(t, err)
+
+	shares, err := shards.ReadShares(sharesFile, 0)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	require.Equal(t, legacy, shares[0].String())
+	require.Equal(t, numbered, shares[1].String())
+
+	err = RunCLI([]string{"recovery-shards", "recover", "-in", sharesFile, "-index-base", "0"})
+	require.NoError(t, err)
+}
+
+func TestCLISecretOutput(t *testing.T) {
+	testDir := t.TempDir()
+	sharesFile := filepath.Join(testDir, "shares.txt")
+	err := os.WriteFile(sharesFile, []byte(`0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery
+0xf606: ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please
+`), 0600)
+	require.NoError(t, err)
+
+	t.Run("out_file", func(t *testing.T) {
+		outputFile := filepath.Join(testDir, "secret.txt")
+		// an existing file is replaced, along with its permissions
+		require.NoError(t, os.WriteFile(outputFile, []byte("old"), 0644))
+
+		err := RunCLI([]string{"recovery-shards", "recover", "-in", sharesFile, "-out-file", outputFile})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		require.Equal(t, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry\n", string(content))
+		info, err := os.Stat(outputFile)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("conflicting_outputs", func(t *testing.T) {
+		err := RunCLI([]string{"recovery-shards", "recover", "-in", sharesFile, "-wipe", "-out-file", filepath.Join(testDir, "other.txt")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only one of -wipe, -out-file and -out-fd can be used")
+
+		err = RunCLI([]string{"recovery-shards", "recover", "-in", sharesFile, "-out-fd", "1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "-out-fd must not be stdout or stderr")
+	})
+
+	t.Run("wipe_requires_terminal", func(t *testing.T) {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			t.Skip("stdin is a terminal")
+		}
+		err := RunCLI([]string{"recovery-shards", "recover", "-in", sharesFile, "-wipe"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "requires an interactive terminal")
+	})
+}
+
+func TestCLIHardening(t *testing.T) {
+	var hardened []secure.Options
+	defaultHarden := harden
+	harden = func(opts secure.Options) []string {
+		hardened = append(hardened, opts)
+		return []string{"swap is active"}
+	}
+	t.Cleanup(func() { harden = defaultHarden })
+
+	mnemonicFile := filepath.Join(t.TempDir(), "mnemonic.txt")
+	require.NoError(t, os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600))
+	stdout, err := captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "split", "-in", mnemonicFile, "-airgap", "off", "-mlock=false"})
+	})
+	require.NoError(t, err)
+	require.Contains(t, stdout, "Warning: swap is active")
+
+	expected := secure.DefaultOptions()
+	expected.LockMemory = false
+	require.Equal(t, []secure.Options{expected}, hardened)
+}
+
+func TestCLIOperationLog(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	err := os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600)
+	require.NoError(t, err)
+	logFile := filepath.Join(testDir, "operations.log")
+
+	err = RunCLI([]string{
+		"recovery-shards",
+		"split",
+		"-n", "3",
+		"-k", "2",
+		"-in", mnemonicFile,
+		"-out", filepath.Join(testDir, "shares") + "/",
+		"-airgap", "off",
+		"-log", logFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "goose")
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"event":"airgap_check","details":{"policy":"off","result":"skipped"}`)
+	require.Contains(t, lines[1], `"event":"split"`)
+	require.Contains(t, lines[1], `"shares":["01`)
+
+	t.Run("invalid_policy", func(t *testing.T) {
+		err := RunCLI([]string{"recovery-shards", "split", "-in", mnemonicFile, "-airgap", "maybe"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown air-gap policy: maybe")
+	})
+}
+
+func TestConfirmTranscriptions(t *testing.T) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal")
+	}
+	share, err := model.ParseMnemonicShare("0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery")
+	require.NoError(t, err)
+	shares := []model.MnemonicShare{share}
+
+	t.Run("prefixes", func(t *testing.T) {
+		input := "0xade1\ndrum wage genu tour slim hung frag lava shop appl larg off chea hove tria phra bag cost sell pers salt amou cute lott\n"
+		err := confirmTranscriptions(io.Discard, shares, 1, bufio.NewScanner(strings.NewReader(input)))
+		require.NoError(t, err)
+	})
+
+	t.Run("retyped_after_mistakes", func(t *testing.T) {
+		wrong := strings.Replace(share.Mnemonic, "bag", "bad", 1)
+		input := "ade1\n" + wrong + "\nade2\n" + share.Mnemonic + "\nade1\n" + share.Mnemonic + "\n"
+		err := confirmTranscriptions(io.Discard, shares, 1, bufio.NewScanner(strings.NewReader(input)))
+		require.NoError(t, err)
+	})
+
+	t.Run("not_confirmed", func(t *testing.T) {
+		input := "ade1\n" + strings.Replace(share.Mnemonic, "lottery", "", 1) + "\n"
+		err := confirmTranscriptions(io.Discard, shares, 1, bufio.NewScanner(strings.NewReader(input)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Share 0xade1 was not confirmed")
+	})
+}
+
+// captureStdout returns what run writes to stdout.
+func captureStdout(t *testing.T, run func() error) (string, error) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- content
+	}()
+	runErr := run()
+	writer.Close()
+	return string(<-output), runErr
+}
+
+func TestCLIJSONOutput(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonic := "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	require.NoError(t, os.WriteFile(mnemonicFile, []byte(mnemonic), 0600))
+	sharesDir := filepath.Join(testDir, "shares") + "/"
+
+	stdout, err := captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-in", mnemonicFile, "-out", sharesDir, "-labels", "Alice,Bob,Carol", "-airgap", "off", "-output", "json"})
+	})
+	require.NoError(t, err)
+
+	var split struct {
+		SchemaVersion int         `json:"schema_version"`
+		Command       string      `json:"command"`
+		Result        splitResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &split), stdout)
+	require.Equal(t, shards.SchemaVersion, split.SchemaVersion)
+	require.Equal(t, "split", split.Command)
+	require.Equal(t, 3, split.Result.Total)
+	require.Equal(t, 2, split.Result.Threshold)
+	require.Len(t, split.Result.Shares, 3)
+	require.Equal(t, "Bob", split.Result.Shares[1].Label)
+	require.Equal(t, filepath.Join(testDir, "shares", "share_2_of_3_bob.txt"), split.Result.Files[1])
+	require.Equal(t, filepath.Join(testDir, "shares", "manifest.json"), split.Result.Manifest)
+	require.NotEmpty(t, split.Result.SetID)
+
+	// the JSON output of split is accepted as a share file
+	jsonFile := filepath.Join(testDir, "split.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(stdout), 0600))
+	stdout, err = captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "recover", "-in", jsonFile, "-manifest", split.Result.Manifest, "-show", "fingerprint", "-airgap", "off", "-output", "json"})
+	})
+	require.NoError(t, err)
+
+	var recovered struct {
+		Result recoverResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &recovered), stdout)
+	require.Equal(t, mnemonic, recovered.Result.Secret)
+	require.True(t, recovered.Result.Verified)
+	require.Equal(t, split.Result.SetID, recovered.Result.SetID)
+	require.Equal(t, []string{"Alice", "Bob", "Carol"}, recovered.Result.Custodians)
+	require.Equal(t, split.Result.Fingerprint, recovered.Result.Wallet.Fingerprint)
+
+	t.Run("share_list", func(t *testing.T) {
+		shares, err := json.Marshal(split.Result.Shares[1:])
+		require.NoError(t, err)
+		listFile := filepath.Join(testDir, "list.json")
+		require.NoError(t, os.WriteFile(listFile, shares, 0600))
+
+		parsed, err := shards.ReadShares(listFile, 1)
+		require.NoError(t, err)
+		require.Equal(t, split.Result.Shares[1:], parsed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		stdout, err := captureStdout(t, func() error {
+			return RunCLI([]string{"recovery-shards", "recover", "-in", filepath.Join(testDir, "missing"), "-airgap", "off", "-output", "json"})
+		})
+		require.Error(t, err)
+
+		var doc jsonDocument
+		require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+		require.Equal(t, "recover", doc.Command)
+		require.Nil(t, doc.Result)
+		require.True(t, strings.HasPrefix(doc.Error.Message, "failed to read path"), doc.Error.Message)
+	})
+
+	t.Run("usage_error", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"recover", "-output", "json", "-unknown"},
+			{"recover", "-unknown", "-output=json"},
+		} {
+			var stdout, stderr bytes.Buffer
+			err := run(append([]string{"recovery-shards"}, args...), &stdout, &stderr)
+			require.Error(t, err)
+			require.Equal(t, exitUsage, exitCode(err))
+
+			var doc jsonDocument
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &doc), stdout.String())
+			require.Equal(t, "recover", doc.Command)
+			require.Equal(t, &jsonError{Message: "flag provided but not defined: -unknown", ExitCode: exitUsage}, doc.Error)
+			require.Contains(t, stderr.String(), "Usage of recover:")
+		}
+	})
+
+	t.Run("final_word", func(t *testing.T) {
+		pipeStdin(t, strings.Join(strings.Fields(mnemonic)[:23], " ")+"\n")
+		stdout, err := captureStdout(t, func() error {
+			return RunCLI([]string{"recovery-shards", "generate", "-final-word", "-airgap", "off", "-output", "json"})
+		})
+		require.NoError(t, err)
+		var doc struct {
+			Result generateResult `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+		require.Len(t, doc.Result.FinalWords, 8)
+		require.Contains(t, doc.Result.FinalWords, "cherry")
+	})
+}
+
+func TestCLIPipe(t *testing.T) {
+	mnemonic := "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"
+
+	pipeStdin(t, mnemonic+"\n")
+	stdout, err := captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-labels", "Alice,Bob,Carol", "-airgap", "off"})
+	})
+	require.NoError(t, err)
+	require.NotContains(t, stdout, "Generated")
+	require.NotContains(t, stdout, "Shares:")
+
+	shares, err := shards.NewDecoder(strings.NewReader(stdout)).Decode()
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+	require.Equal(t, "Carol", shares[2].Label)
+
+	// the shares are read in any supported format
+	var piped bytes.Buffer
+	encoder := shards.NewEncoder(&piped)
+	encoder.Format = wordcode.Prefix
+	require.NoError(t, encoder.Encode(shares[0]))
+	require.NoError(t, encoder.Encode(shares[2]))
+
+	pipeStdin(t, piped.String())
+	stdout, err = captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "recover", "-airgap", "off"})
+	})
+	require.NoError(t, err)
+	require.Equal(t, mnemonic+"\n", stdout)
+
+	t.Run("confirm", func(t *testing.T) {
+		pipeStdin(t, mnemonic)
+		err := RunCLI([]string{"recovery-shards", "split", "-confirm", "-airgap", "off"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "-confirm cannot be used with a secret piped to stdin")
+	})
+
+	t.Run("invalid_share", func(t *testing.T) {
+		pipeStdin(t, "# shares\n"+strings.Replace(shares[0].String(), "Share 1 of", "Share 2 of", 1)+"\n")
+		err := RunCLI([]string{"recovery-shards", "recover", "-airgap", "off"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading stdin: line 2")
+	})
+}
+
+func TestCLIRecoverPaths(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonic := "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	require.NoError(t, os.WriteFile(mnemonicFile, []byte(mnemonic), 0600))
+	sharesDir := filepath.Join(testDir, "shares")
+	err := RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-in", mnemonicFile, "-out", sharesDir + "/", "-airgap", "off"})
+	require.NoError(t, err)
+
+	// the shares are moved to one folder per custodian, with a copy of the
+	// first one in the second folder
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("share_%d_of_3.txt", i)
+		custodian := filepath.Join(testDir, "media", fmt.Sprintf("usb%d", i), "shares")
+		require.NoError(t, os.MkdirAll(custodian, 0700))
+		require.NoError(t, os.Rename(filepath.Join(sharesDir, name), filepath.Join(custodian, name)))
+	}
+	first := filepath.Join(testDir, "media", "usb1", "shares", "share_1_of_3.txt")
+	content, err := os.ReadFile(first)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "media", "usb2", "shares", "copy.txt"), content, 0600))
+
+	recoverJSON := func(args ...string) recoverResult {
+		t.Helper()
+		stdout, err := captureStdout(t, func() error {
+			return RunCLI(append([]string{"recovery-shards", "recover", "-airgap", "off", "-output", "json"}, args...))
+		})
+		require.NoError(t, err, stdout)
+		var doc struct {
+			Result recoverResult `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+		require.Equal(t, mnemonic, doc.Result.Secret)
+		return doc.Result
+	}
+
+	t.Run("recursive_glob", func(t *testing.T) {
+		result := recoverJSON("-recursive", "-manifest", filepath.Join(sharesDir, "manifest.json"), "-in", filepath.Join(testDir, "media", "usb*"))
+		require.Len(t, result.Shares, 3)
+		require.True(t, result.Verified)
+		id := result.Shares[0]
+		require.Equal(t, []string{first, filepath.Join(testDir, "media", "usb2", "shares", "copy.txt")}, result.Sources[id])
+	})
+
+	t.Run("several_paths", func(t *testing.T) {
+		result := recoverJSON("-in", filepath.Join(testDir, "media", "usb1", "shares"), filepath.Join(testDir, "media", "usb2", "shares"))
+		require.Len(t, result.Shares, 2)
+		require.Len(t, result.Sources[result.Shares[0]], 2)
+	})
+
+	t.Run("not_recursive", func(t *testing.T) {
+		err := RunCLI([]string{"recovery-shards", "recover", "-airgap", "off", "-in", filepath.Join(testDir, "media")})
+		require.Error(t, err)
+		require.Equal(t, exitInsufficientShares, exitCode(err))
+	})
+}
+
+func TestCLIInspect(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	require.NoError(t, os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600))
+	sharesDir := filepath.Join(testDir, "shares")
+	err := RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-in", mnemonicFile, "-out", sharesDir + "/", "-labels", "Alice,Bob,Carol", "-airgap", "off"})
+	require.NoError(t, err)
+	shareFile := filepath.Join(sharesDir, "share_2_of_3_bob.txt")
+	manifest, err := shards.FindManifest(sharesDir)
+	require.NoError(t, err)
+
+	stdout, err := captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "inspect", "-output", "json", shareFile})
+	})
+	require.NoError(t, err)
+	var doc struct {
+		Result inspectResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+	require.Len(t, doc.Result.Shares, 1)
+	info := doc.Result.Shares[0]
+	require.Equal(t, "Bob", info.Label)
+	require.Equal(t, 2, info.FormatVersion)
+	require.Equal(t, 2, info.XCoordinate)
+	require.Equal(t, 24, info.Words)
+	require.Equal(t, 32, info.PayloadLength)
+	require.True(t, info.BIP39Checksum)
+	require.True(t, info.ShareChecksum)
+	require.Equal(t, manifest.SetID, info.SetID)
+	require.Equal(t, 2, info.Threshold)
+	require.Equal(t, 3, info.Total)
+
+	shares, err := shards.ReadShares(shareFile, 1)
+	require.NoError(t, err)
+	share := shares[0]
+
+	t.Run("text", func(t *testing.T) {
+		stdout, err := captureStdout(t, func() error {
+			return RunCLI([]string{"recovery-shards", "inspect", share.String()})
+		})
+		require.NoError(t, err)
+		require.Contains(t, stdout, "Share 2 of 3 (0x")
+		require.Contains(t, stdout, "Share checksum: valid")
+		require.Contains(t, stdout, "Set ID: unknown, no manifest found")
+		require.Contains(t, stdout, "Threshold: unknown, 3 shares in total")
+		require.NotContains(t, stdout, share.Mnemonic)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		words := strings.Fields(share.Mnemonic)
+		words[0], words[1] = words[1], words[0]
+		malformed := share
+		malformed.Mnemonic = strings.Join(words, " ")
+		err := RunCLI([]string{"recovery-shards", "inspect", malformed.String()})
+		require.Error(t, err)
+		require.Equal(t, exitInvalidShare, exitCode(err))
+
+		err = RunCLI([]string{"recovery-shards", "inspect", "Share 2 of 3 (0x02ff):"})
+		require.Error(t, err)
+		require.Equal(t, exitInvalidShare, exitCode(err))
+	})
+
+	t.Run("checksums", func(t *testing.T) {
+		legacy := "0xade1: drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"
+		testCases := []struct {
+			name             string
+			share            string
+			bip39, checkByte bool
+		}{
+			{
+				// loud only differs from lottery in the BIP-39 checksum bits
+				name:      "bip39",
+				share:     strings.Replace(legacy, "lottery", "loud", 1),
+				checkByte: true,
+			},
+			{
+				name:  "check_byte",
+				share: strings.Replace(legacy, "0xade1", "0xade2", 1),
+				bip39: true,
+			},
+			{
+				name:  "swapped_words",
+				share: strings.Replace(legacy, "drum wage", "wage drum", 1),
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				stdout, err := captureStdout(t, func() error {
+					return RunCLI([]string{"recovery-shards", "inspect", "-output", "json", tc.share})
+				})
+				require.Error(t, err)
+				require.Equal(t, exitInvalidShare, exitCode(err))
+				var doc struct {
+					Result inspectResult `json:"result"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+				require.Len(t, doc.Result.Shares, 1)
+				require.Equal(t, tc.bip39, doc.Result.Shares[0].BIP39Checksum)
+				require.Equal(t, tc.checkByte, doc.Result.Shares[0].ShareChecksum)
+			})
+		}
+
+		stdout, err := captureStdout(t, func() error {
+			return RunCLI([]string{"recovery-shards", "inspect", testCases[1].share})
+		})
+		require.Error(t, err)
+		require.Contains(t, stdout, "BIP-39 checksum: valid")
+		require.Contains(t, stdout, "Share checksum: invalid (0xe2)")
+	})
+
+	t.Run("other_set", func(t *testing.T) {
+		other := filepath.Join(testDir, "other")
+		err := RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-in", mnemonicFile, "-out", other + "/", "-airgap", "off"})
+		require.NoError(t, err)
+		err = RunCLI([]string{"recovery-shards", "inspect", "-manifest", filepath.Join(other, "manifest.json"), shareFile})
+		require.Error(t, err)
+		require.Equal(t, exitMixedSets, exitCode(err))
+	})
+}
+
+func TestCLIDoctor(t *testing.T) {
+	testDir := t.TempDir()
+	mnemonicFile := filepath.Join(testDir, "mnemonic.txt")
+	require.NoError(t, os.WriteFile(mnemonicFile, []byte("goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"), 0600))
+	sharesDir := filepath.Join(testDir, "shares")
+	err := RunCLI([]string{"recovery-shards", "split", "-n", "3", "-k", "2", "-in", mnemonicFile, "-out", sharesDir + "/", "-airgap", "off"})
+	require.NoError(t, err)
+
+	stdout, err := captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "doctor", "-in", sharesDir})
+	})
+	require.NoError(t, err)
+	require.Contains(t, stdout, "Checked 3 files:")
+	require.Contains(t, stdout, "3 of 3 usable shares, 2 required")
+	require.Contains(t, stdout, "matched to the set by their identifier only")
+	require.Contains(t, stdout, "Result: GO")
+
+	// a loose copy of the notes, and a share readable by anyone
+	require.NoError(t, os.WriteFile(filepath.Join(sharesDir, "notes.txt"), []byte("Call Bob before recovering.\n"), 0600))
+	require.NoError(t, os.Chmod(filepath.Join(sharesDir, "share_1_of_3.txt"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(sharesDir, "share_2_of_3.txt")))
+	stdout, err = captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "doctor", sharesDir})
+	})
+	require.NoError(t, err)
+	require.Contains(t, stdout, "[warning] "+filepath.Join(sharesDir, "notes.txt")+" holds no shares")
+	require.Contains(t, stdout, "has permissions 0644, expected 0600")
+	require.Contains(t, stdout, "Result: GO")
+
+	require.NoError(t, os.Remove(filepath.Join(sharesDir, "share_3_of_3.txt")))
+	stdout, err = captureStdout(t, func() error {
+		return RunCLI([]string{"recovery-shards", "doctor", "-output", "json", sharesDir})
+	})
+	require.Error(t, err)
+	require.Equal(t, exitNoGo, exitCode(err))
+	var doc struct {
+		Error  *jsonError   `json:"error"`
+		Result doctorResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &doc), stdout)
+	require.NotNil(t, doc.Error)
+	require.Equal(t, exitNoGo, doc.Error.ExitCode)
+	require.False(t, doc.Result.Go)
+	require.Equal(t, 1, doc.Result.Usable)
+	require.Equal(t, "0644", doc.Result.Files[1].Mode)
+	require.Contains(t, doc.Result.Problems, shards.Problem{Kind: shards.ProblemThreshold, Blocking: true, Message: fmt.Sprintf("share set %s requires 2 shares to recover, found 1 usable", doc.Result.SetID)})
+
+	err = RunCLI([]string{"recovery-shards", "doctor"})
+	require.Equal(t, exitUsage, exitCode(err))
+
+	err = RunCLI([]string{"recovery-shards"})
+	require.Equal(t, exitUsage, exitCode(err))
+	require.EqualError(t, err, "expected 'generate', 'split', 'recover', 'inspect', 'doctor', or 'version' subcommand")
+}
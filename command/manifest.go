@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+// NewManifest creates the manifest of the shares of a split secret, including
+// its BIP32 master fingerprint when it has one.
+func NewManifest(secret seed.Secret, shares []model.MnemonicShare, k int) (*model.Manifest, error) {
+	payload, err := secret.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret: %w", err)
+	}
+	defer secure.Wipe(payload)
+
+	manifest, err := model.NewManifest(payload, shares, k)
+	if err != nil {
+		return nil, err
+	}
+	manifest.SecretType = string(secret.Type)
+	if master, err := secret.MasterKey(); err == nil {
+		manifest.Fingerprint = fmt.Sprintf("%x", master.Fingerprint())
+	}
+	return manifest, nil
+}
+
+// RecoverWithManifest combines the shares like RecoverSecret, but first checks
+// that they belong to the share set of the manifest and then that the
+// recovered secret is the one the manifest was created for.
+func RecoverWithManifest(manifest *model.Manifest, shares []model.MnemonicShare) (seed.Secret, error) {
+	if err := manifest.CheckShares(shares); err != nil {
+		return seed.Secret{}, err
+	}
+
+	secret, err := RecoverSecret(shares)
+	if err != nil {
+		return seed.Secret{}, err
+	}
+
+	payload, err := secret.Payload()
+	if err != nil {
+		secret.Wipe()
+		return seed.Secret{}, fmt.Errorf("failed to encode secret: %w", err)
+	}
+	defer secure.Wipe(payload)
+	if err := manifest.VerifySecret(payload); err != nil {
+		secret.Wipe()
+		return seed.Secret{}, err
+	}
+	if manifest.SecretType != "" && manifest.SecretType != string(secret.Type) {
+		secret.Wipe()
+		return seed.Secret{}, fmt.Errorf("recovered %s but share set %s is of a %s", secret.Type.Description(), manifest.SetID, seed.Type(manifest.SecretType).Description())
+	}
+	return secret, nil
+}
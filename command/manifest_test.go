@@ -0,0 +1,50 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/entropy"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+func TestRecoverWithManifest(t *testing.T) {
+	secret, err := seed.Parse(seed.BIP39, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry")
+	require.NoError(t, err)
+
+	shares, err := SplitSecretWithRand(secret, 3, 2, entropy.NewDRBG([]byte("manifest test")))
+	require.NoError(t, err)
+	manifest, err := NewManifest(secret, shares, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bip39", manifest.SecretType)
+	assert.Len(t, manifest.Fingerprint, 8)
+
+	recovered, err := RecoverWithManifest(manifest, shares[1:])
+	require.NoError(t, err)
+	assert.Equal(t, secret, recovered)
+
+	t.Run("shares_from_another_split", func(t *testing.T) {
+		// a fixed seed, as share 2 of another split has the same identifier
+		// as share 2 of the set 1 time in 256
+		otherShares, err := SplitSecretWithRand(secret, 3, 2, entropy.NewDRBG([]byte("another split")))
+		require.NoError(t, err)
+		require.NotEqual(t, shares[1].Identifier, otherShares[1].Identifier)
+
+		_, err = RecoverWithManifest(manifest, []model.MnemonicShare{shares[0], otherShares[1]})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not part of share set "+manifest.SetID)
+	})
+
+	t.Run("different_secret", func(t *testing.T) {
+		other, err := seed.Parse(seed.BIP39, "happy wet injury knee buddy anger ordinary ketchup bread oxygen puzzle hip mechanic sunny monitor exit join spy awkward degree island task eternal sniff")
+		require.NoError(t, err)
+		otherManifest, err := NewManifest(other, shares, 2)
+		require.NoError(t, err)
+
+		_, err = RecoverWithManifest(otherManifest, shares[:2])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "recovered secret does not match the manifest")
+	})
+}
@@ -1,33 +1,90 @@
 package command
 
 import (
+	"bytes"
 	"fmt"
+	"slices"
 
-	"github.com/hashicorp/vault/shamir"
-	"github.com/tyler-smith/go-bip39"
 	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shamir"
 )
 
+// Recover combines the shares and returns the text form of the recovered
+// secret, which is the mnemonic phrase for shares of a BIP39 mnemonic.
 func Recover(shares []model.MnemonicShare) (string, error) {
+	secret, err := RecoverSecret(shares)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := secret.Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", secret.Type.Description(), err)
+	}
+	return text, nil
+}
+
+// RecoverSecret combines the shares and decodes the recovered secret. A share
+// given several times is only used once.
+func RecoverSecret(shares []model.MnemonicShare) (seed.Secret, error) {
+	shares, err := distinctShares(shares)
+	if err != nil {
+		return seed.Secret{}, err
+	}
+	if len(shares) < 2 {
+		return seed.Secret{}, &model.ErrInsufficientShares{Have: len(shares), Need: 2}
+	}
+
 	// Convert mnemonics to entropy
 	completeShares := make([][]byte, len(shares))
+	defer secure.Wipe(completeShares...)
 	for i, share := range shares {
 		shamirShare, err := share.ToShamir()
 		if err != nil {
-			return "", fmt.Errorf("failed to convert share %d to shamir share: %w", i+1, err)
+			return seed.Secret{}, fmt.Errorf("failed to convert share %d to shamir share: %w", i+1, err)
 		}
 		completeShares[i] = shamirShare
 	}
 
-	recoveredEntropy, err := shamir.Combine(completeShares)
+	// shares of the same split have the same length and total
+	for i, share := range shares[1:] {
+		sameTotal := share.Total == 0 || shares[0].Total == 0 || share.Total == shares[0].Total
+		if !sameTotal || len(completeShares[i+1]) != len(completeShares[0]) {
+			return seed.Secret{}, &model.ErrMixedSets{ShareID: fmt.Sprintf("%04x", share.Identifier)}
+		}
+	}
+
+	payload, err := shamir.Combine(completeShares)
 	if err != nil {
-		return "", fmt.Errorf("failed to recover secret: %w", err)
+		return seed.Secret{}, fmt.Errorf("failed to recover secret: %w", err)
 	}
+	defer secure.Wipe(payload)
 
-	mnemonic, err := bip39.NewMnemonic(recoveredEntropy)
+	secret, err := seed.FromPayload(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate mnemonic: %w", err)
+		return seed.Secret{}, fmt.Errorf("failed to decode secret: %w", err)
 	}
 
-	return mnemonic, nil
+	return secret, nil
+}
+
+// distinctShares drops the copies of the shares given several times. Different
+// shares with the same number cannot be of the same split, so an error is
+// returned for them.
+func distinctShares(shares []model.MnemonicShare) ([]model.MnemonicShare, error) {
+	distinct := make([]model.MnemonicShare, 0, len(shares))
+	for _, share := range shares {
+		i := slices.IndexFunc(distinct, func(other model.MnemonicShare) bool {
+			return share.Number() > 0 && other.Number() == share.Number()
+		})
+		switch {
+		case i < 0:
+			distinct = append(distinct, share)
+		case !bytes.Equal(distinct[i].Identifier, share.Identifier) || distinct[i].Mnemonic != share.Mnemonic:
+			return nil, &model.ErrMixedSets{ShareID: fmt.Sprintf("%04x", share.Identifier)}
+		}
+	}
+	return distinct, nil
 }
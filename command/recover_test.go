@@ -1,6 +1,7 @@
 package command
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,7 +27,7 @@ func TestRecoverErrors(t *testing.T) {
 			shares: []model.MnemonicShare{
 				mustMnemonicShare("0x0110", "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"),
 			},
-			errMsg: "less than two parts cannot be used to reconstruct the secret",
+			errMsg: "at least 2 shares are required to recover the secret",
 		},
 		{
 			name: "invalid_share",
@@ -69,6 +70,46 @@ func TestRecoverErrors(t *testing.T) {
 	}
 }
 
+func TestRecoverSecretErrors(t *testing.T) {
+	share := mustMnemonicShare("0xade1", "drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery")
+
+	_, err := RecoverSecret([]model.MnemonicShare{share})
+	var insufficient *model.ErrInsufficientShares
+	require.ErrorAs(t, err, &insufficient)
+	assert.Equal(t, model.ErrInsufficientShares{Have: 1, Need: 2}, *insufficient)
+
+	short, err := model.NewMnemonicShareFromShamir(append(make([]byte, 16), 0x10))
+	require.NoError(t, err)
+	_, err = RecoverSecret([]model.MnemonicShare{share, short})
+	var mixed *model.ErrMixedSets
+	require.ErrorAs(t, err, &mixed)
+	assert.Equal(t, fmt.Sprintf("%04x", short.Identifier), mixed.ShareID)
+
+	corrupt := mustMnemonicShare("0xf606", "ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please")
+	corrupt.Identifier = []byte{0xf6, 0x07}
+	_, err = RecoverSecret([]model.MnemonicShare{share, corrupt})
+	var checksum *model.ErrChecksumMismatch
+	require.ErrorAs(t, err, &checksum)
+	assert.Equal(t, "f607", checksum.ShareID)
+
+	// a share of another split with the same number as 0xade1
+	other := mustMnemonicShare("0xadbb", "book wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute mercy")
+	second := mustMnemonicShare("0xf606", "ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please")
+	_, err = RecoverSecret([]model.MnemonicShare{share, other, second})
+	require.ErrorAs(t, err, &mixed)
+	assert.Equal(t, "adbb", mixed.ShareID)
+
+	// the copies of a share are only used once
+	_, err = RecoverSecret([]model.MnemonicShare{share, share})
+	require.ErrorAs(t, err, &insufficient)
+	assert.Equal(t, model.ErrInsufficientShares{Have: 1, Need: 2}, *insufficient)
+	secret, err := RecoverSecret([]model.MnemonicShare{share, second, share})
+	require.NoError(t, err)
+	text, err := secret.Text()
+	require.NoError(t, err)
+	assert.Equal(t, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry", text)
+}
+
 func mustMnemonicShare(identifier string, mnemonic string) model.MnemonicShare {
 	share, err := model.NewMnemonicShare(identifier, mnemonic)
 	if err != nil {
@@ -76,3 +117,19 @@ func mustMnemonicShare(identifier string, mnemonic string) model.MnemonicShare {
 	}
 	return share
 }
+
+// TestRecoverVaultShares recovers a full set of shares made by Split when it
+// used github.com/hashicorp/vault/shamir, from every pair of shares.
+func TestRecoverVaultShares(t *testing.T) {
+	shares := []model.MnemonicShare{
+		mustMnemonicShare("0xd3e8", "scrap fold tenant lamp hawk deliver load trouble lake strike burger piece mass apart voice glove write process split cart bachelor twin later either"),
+		mustMnemonicShare("0x09a7", "paddle discover gallery fuel execute mention sick camp coast sand office erupt borrow alcohol gym found great shy junior rule museum tail wild royal"),
+		mustMnemonicShare("0xb9f7", "clutch hollow tennis odor amazing frown steak voyage high label avoid region couch exhibit drive frown pig photo two bright pluck acoustic mesh favorite"),
+	}
+
+	for _, combination := range generateCombinations(shares, 2) {
+		mnemonic, err := Recover(combination)
+		require.NoError(t, err)
+		assert.Equal(t, "excuse glare tenant iron tide march health hurdle hood venue soldier file school soon digital estate fox hazard mesh cross right effort whale whip", mnemonic)
+	}
+}
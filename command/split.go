@@ -1,11 +1,16 @@
 package command
 
 import (
+	"bytes"
+	"crypto/rand"
 	"fmt"
+	"io"
 
-	"github.com/hashicorp/vault/shamir"
 	"github.com/tyler-smith/go-bip39"
 	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shamir"
 )
 
 func Split(mnemonic string, n, k int) ([]model.MnemonicShare, error) {
@@ -18,10 +23,30 @@ func Split(mnemonic string, n, k int) ([]model.MnemonicShare, error) {
 		return nil, fmt.Errorf("failed to get entropy: %w", err)
 	}
 
-	shares, err := shamir.Split(entropy, n, k)
+	return SplitSecret(seed.Secret{Type: seed.BIP39, Data: entropy}, n, k)
+}
+
+// SplitSecret splits a secret of any supported type into n shares, k of which
+// are required to recover it.
+func SplitSecret(secret seed.Secret, n, k int) ([]model.MnemonicShare, error) {
+	return SplitSecretWithRand(secret, n, k, rand.Reader)
+}
+
+// SplitSecretWithRand is like SplitSecret, but reads all the randomness of the
+// split from rand. Passing a deterministic source, like an entropy.DRBG with a
+// fixed seed, always produces the same shares.
+func SplitSecretWithRand(secret seed.Secret, n, k int, rand io.Reader) ([]model.MnemonicShare, error) {
+	payload, err := secret.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret: %w", err)
+	}
+	defer secure.Wipe(payload)
+
+	shares, err := shamir.Split(payload, n, k, rand)
 	if err != nil {
 		return nil, fmt.Errorf("failed to split secret: %w", err)
 	}
+	defer secure.Wipe(shares...)
 
 	result := make([]model.MnemonicShare, len(shares))
 	for i, share := range shares {
@@ -29,6 +54,7 @@ func Split(mnemonic string, n, k int) ([]model.MnemonicShare, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create mnemonic for share %d: %w", i+1, err)
 		}
+		mnemShare.Total = n
 		result[i] = mnemShare
 	}
 
@@ -53,6 +79,28 @@ func VerifyShares(originalMnemonic string, shares []model.MnemonicShare, k int)
 	return nil
 }
 
+// VerifySecretShares checks that every combination of k shares recovers the
+// original secret.
+func VerifySecretShares(original seed.Secret, shares []model.MnemonicShare, k int) error {
+	if len(shares) < k {
+		return fmt.Errorf("not enough shares to verify")
+	}
+
+	for _, combination := range generateCombinations(shares, k) {
+		secret, err := RecoverSecret(combination)
+		if err != nil {
+			return fmt.Errorf("failed to recover secret: %w", err)
+		}
+		matches := secret.Type == original.Type && bytes.Equal(secret.Data, original.Data)
+		secret.Wipe()
+		if !matches {
+			return fmt.Errorf("secret does not match")
+		}
+	}
+
+	return nil
+}
+
 func generateCombinations(shares []model.MnemonicShare, k int) [][]model.MnemonicShare {
 	if k > len(shares) {
 		return [][]model.MnemonicShare{}
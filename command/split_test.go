@@ -1,11 +1,18 @@
 package command
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/entropy"
+	"github.com/victorges/recovery-shards/seed"
 )
 
 func TestSplit(t *testing.T) {
@@ -93,3 +100,45 @@ func TestSplitErrors(t *testing.T) {
 		})
 	}
 }
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestSplitGolden splits secrets with a deterministic randomness source and
+// compares the shares with testdata/split.golden, so any change to the share
+// format or to the split algorithm is caught. Run with -update to regenerate.
+func TestSplitGolden(t *testing.T) {
+	secrets := []struct{ seedType, text string }{
+		{"bip39", "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"},
+		{"bip39", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+		{"xprv", "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+		{"wif", "Kx45GeUBSMPReYQwgXiKhG9FzNXrnCeutJp4yjTd5kKxCitadm3C"},
+	}
+
+	var golden strings.Builder
+	for i, s := range secrets {
+		secret, err := seed.Parse(seed.Type(s.seedType), s.text)
+		require.NoError(t, err)
+
+		rand := entropy.NewDRBG([]byte(fmt.Sprintf("recovery-shards golden %d", i)))
+		shares, err := SplitSecretWithRand(secret, 3, 2, rand)
+		require.NoError(t, err)
+
+		fmt.Fprintf(&golden, "# %s: %s\n", s.seedType, s.text)
+		for _, share := range shares {
+			fmt.Fprintln(&golden, share)
+		}
+
+		recovered, err := RecoverSecret(shares[1:])
+		require.NoError(t, err)
+		assert.Equal(t, secret, recovered)
+	}
+
+	path := filepath.Join("testdata", "split.golden")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, []byte(golden.String()), 0644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), golden.String())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victorges/recovery-shards/shards"
+)
+
+// errNoGo is returned by doctor when the shares cannot be recovered as they
+// are.
+var errNoGo = errors.New("the shares cannot be recovered as they are")
+
+// doctorFile is a file checked by doctor.
+type doctorFile struct {
+	Path string `json:"path"`
+	// Mode is the permissions of the file, in octal like 0600
+	Mode   string   `json:"mode"`
+	Shares []string `json:"shares"`
+}
+
+// doctorResult is the JSON result of doctor.
+type doctorResult struct {
+	// Go is whether the shares found can be recovered as they are
+	Go        bool         `json:"go"`
+	SetID     string       `json:"set_id,omitempty"`
+	Threshold int          `json:"threshold,omitempty"`
+	Total     int          `json:"total,omitempty"`
+	Usable    int          `json:"usable"`
+	Files     []doctorFile `json:"files"`
+	// Shares lists the files each share was found in, by hex identifier
+	Shares   map[string][]string `json:"shares"`
+	Problems []shards.Problem    `json:"problems"`
+}
+
+// newDoctorResult converts the report of shards.Audit to the result of doctor.
+func newDoctorResult(report *shards.AuditReport) doctorResult {
+	result := doctorResult{
+		Go:       report.Go(),
+		Usable:   report.Usable,
+		Files:    make([]doctorFile, len(report.Files)),
+		Shares:   shareSources(report.Shares),
+		Problems: report.Problems,
+	}
+	if manifest := report.Manifest; manifest != nil {
+		result.SetID, result.Threshold, result.Total = manifest.SetID, manifest.Threshold, manifest.Total
+	}
+	for i, file := range report.Files {
+		result.Files[i] = doctorFile{Path: file.Path, Mode: fmt.Sprintf("%04o", uint32(file.Mode)), Shares: file.Shares}
+	}
+	if result.Problems == nil {
+		result.Problems = []shards.Problem{}
+	}
+	return result
+}
+
+// printDoctorReport prints the files checked by doctor, the share set, the
+// problems found and whether the shares can be recovered.
+func printDoctorReport(messages io.Writer, result doctorResult) {
+	fmt.Fprintf(messages, "Checked %d files:\n", len(result.Files))
+	for _, file := range result.Files {
+		shares := "no shares"
+		if len(file.Shares) > 0 {
+			shares = "0x" + strings.Join(file.Shares, ", 0x")
+		}
+		fmt.Fprintf(messages, "  %s (%s): %s\n", file.Path, file.Mode, shares)
+	}
+	fmt.Fprintln(messages)
+
+	if result.SetID != "" {
+		fmt.Fprintf(messages, "Share set %s: %d of %d usable shares, %d required\n", result.SetID, result.Usable, result.Total, result.Threshold)
+		fmt.Fprintln(messages, setMembershipNote)
+	} else {
+		fmt.Fprintf(messages, "Share set: unknown, no manifest found, %d usable shares\n", result.Usable)
+	}
+
+	if len(result.Problems) > 0 {
+		fmt.Fprintln(messages)
+		fmt.Fprintln(messages, "Problems:")
+		for _, problem := range result.Problems {
+			severity := "warning"
+			if problem.Blocking {
+				severity = "error"
+			}
+			fmt.Fprintf(messages, "  [%s] %s\n", severity, problem.Message)
+		}
+	}
+
+	fmt.Fprintln(messages)
+	if result.Go {
+		fmt.Fprintln(messages, "Result: GO, the shares can be recovered")
+	} else {
+		fmt.Fprintln(messages, "Result: NO-GO, fix the errors above before recovering")
+	}
+}
@@ -0,0 +1,60 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// chiSquareCritical holds the chi-square values with a p-value of 0.001 for the
+// degrees of freedom of each alphabet size.
+var chiSquareCritical = map[int]float64{
+	2:  10.83,
+	6:  20.52,
+	16: 37.70,
+}
+
+// minBiasSamples is the number of symbols below which frequency checks are
+// meaningless and skipped.
+const minBiasSamples = 20
+
+// biasWarnings looks for signs of a biased input: a symbol distribution that
+// is very unlikely for a fair die or coin, and suspiciously long runs of the
+// same symbol.
+func biasWarnings(symbols, alphabet string) []string {
+	var warnings []string
+	n := len(symbols)
+	if n < minBiasSamples {
+		return nil
+	}
+
+	expected := float64(n) / float64(len(alphabet))
+	chiSquare := 0.0
+	for _, c := range alphabet {
+		count := strings.Count(symbols, string(c))
+		chiSquare += math.Pow(float64(count)-expected, 2) / expected
+		if count == 0 && expected >= 5 {
+			warnings = append(warnings, fmt.Sprintf("symbol %c never appears in %d symbols", c, n))
+		}
+	}
+	if chiSquare > chiSquareCritical[len(alphabet)] {
+		warnings = append(warnings, fmt.Sprintf("symbol frequencies are very uneven (chi-square %.1f), the input may be biased", chiSquare))
+	}
+
+	// A run of length r starts at a given position with probability k^-(r-1),
+	// so flag runs that are expected less than once in a thousand inputs
+	maxRun := 1 + int(math.Ceil(math.Log(float64(n)*1000)/math.Log(float64(len(alphabet)))))
+	run, longest := 1, 1
+	for i := 1; i < n; i++ {
+		if symbols[i] == symbols[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	if longest >= maxRun {
+		warnings = append(warnings, fmt.Sprintf("the same symbol repeats %d times in a row, the input may not be random", longest))
+	}
+	return warnings
+}
@@ -0,0 +1,75 @@
+package entropy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// DRBG is a deterministic random bit generator: HMAC_DRBG with SHA-256 from
+// NIST SP 800-90A, without reseeding or additional input. The same seed always
+// produces the same output for the same sequence of reads.
+type DRBG struct {
+	key, value []byte
+}
+
+// NewDRBG returns a DRBG instantiated with the concatenation of the seed
+// parts.
+func NewDRBG(seed ...[]byte) *DRBG {
+	d := &DRBG{
+		key:   make([]byte, sha256.Size),
+		value: make([]byte, sha256.Size),
+	}
+	for i := range d.value {
+		d.value[i] = 0x01
+	}
+
+	var material []byte
+	for _, part := range seed {
+		material = append(material, part...)
+	}
+	d.update(material)
+	clear(material)
+	return d
+}
+
+// Read fills p with pseudorandom bytes. It never fails.
+func (d *DRBG) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		d.value = d.hmac(d.value)
+		n += copy(p[n:], d.value)
+	}
+	d.update(nil)
+	return len(p), nil
+}
+
+func (d *DRBG) update(data []byte) {
+	d.key = d.hmac(d.value, []byte{0x00}, data)
+	d.value = d.hmac(d.value)
+	if len(data) > 0 {
+		d.key = d.hmac(d.value, []byte{0x01}, data)
+		d.value = d.hmac(d.value)
+	}
+}
+
+func (d *DRBG) hmac(data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, d.key)
+	for _, b := range data {
+		mac.Write(b)
+	}
+	return mac.Sum(nil)
+}
+
+// MixedReader returns a source of randomness that combines rand with extra
+// entropy supplied by the user: a DRBG seeded with 32 bytes read from rand
+// followed by the extra entropy. Its output is unpredictable as long as either
+// of the two is.
+func MixedReader(rand io.Reader, extra []byte) (io.Reader, error) {
+	random := make([]byte, sha256.Size)
+	defer clear(random)
+	if _, err := io.ReadFull(rand, random); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return NewDRBG(random, extra), nil
+}
@@ -0,0 +1,135 @@
+// Package entropy reads user-supplied entropy, like dice rolls or coin flips,
+// to be used instead of (or mixed with) the operating system RNG when
+// generating a mnemonic.
+package entropy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Source identifies the kind of user-supplied entropy.
+type Source string
+
+const (
+	// Dice are rolls of a six-sided die, as digits 1 to 6.
+	Dice Source = "dice"
+	// Coins are coin flips, as h/t or 1/0.
+	Coins Source = "coins"
+	// Hex is hexadecimal digits, like from a 16-sided die.
+	Hex Source = "hex"
+)
+
+// alphabets holds the normalized symbols of each source.
+var alphabets = map[Source]string{
+	Dice:  "123456",
+	Coins: "01",
+	Hex:   "0123456789abcdef",
+}
+
+// ParseSource parses the name of an entropy source.
+func ParseSource(name string) (Source, error) {
+	s := Source(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := alphabets[s]; !ok {
+		return "", fmt.Errorf("unknown entropy source: %s (expected dice, coins or hex)", name)
+	}
+	return s, nil
+}
+
+// UserEntropy is entropy supplied by the user.
+type UserEntropy struct {
+	Source Source
+	// Symbols holds the normalized input, one character per roll or flip
+	Symbols string
+	// Bits is the entropy of the input, assuming it is unbiased
+	Bits float64
+	// Warnings lists signs that the input may be biased
+	Warnings []string
+}
+
+// Read parses the user input for the source. Whitespace and common separators
+// are ignored, and coin flips may be given as h/t or 1/0.
+func Read(source Source, input string) (*UserEntropy, error) {
+	alphabet, ok := alphabets[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown entropy source: %s", source)
+	}
+
+	var symbols strings.Builder
+	for i, r := range strings.ToLower(input) {
+		switch {
+		case strings.ContainsRune(" \t\r\n,;-", r):
+			continue
+		case source == Coins && r == 'h':
+			r = '1'
+		case source == Coins && r == 't':
+			r = '0'
+		}
+		if !strings.ContainsRune(alphabet, r) {
+			return nil, fmt.Errorf("invalid %s input %q at position %d", source, r, i)
+		}
+		symbols.WriteRune(r)
+	}
+
+	u := &UserEntropy{
+		Source:  source,
+		Symbols: symbols.String(),
+		Bits:    float64(symbols.Len()) * math.Log2(float64(len(alphabet))),
+	}
+	u.Warnings = biasWarnings(u.Symbols, alphabet)
+	return u, nil
+}
+
+// Entropy returns size bytes of entropy derived from the input. It fails if
+// the input provides less than size*8 bits of entropy, rounded to the nearest
+// bit so that the usual 99 dice rolls are enough for 256 bits. Coin flips and hex
+// digits providing exactly the required bits are used as they are, so the
+// resulting words can be checked by hand, while any other input is hashed
+// with SHA-256 (for dice rolls, this matches the Coldcard method).
+func (u *UserEntropy) Entropy(size int) ([]byte, error) {
+	if size <= 0 || size > sha256.Size {
+		return nil, fmt.Errorf("invalid entropy size: %d bytes", size)
+	}
+	if required := float64(size * 8); math.Round(u.Bits) < required {
+		return nil, fmt.Errorf("not enough entropy: %d %s symbols provide %.0f bits, %.0f bits are required (%d symbols)",
+			len(u.Symbols), u.Source, u.Bits, required, u.RequiredSymbols(size))
+	}
+
+	switch {
+	case u.Source == Hex && len(u.Symbols) == size*2:
+		return hex.DecodeString(u.Symbols)
+	case u.Source == Coins && len(u.Symbols) == size*8:
+		return bitsToBytes(u.Symbols), nil
+	}
+
+	hash := sha256.Sum256([]byte(u.Symbols))
+	return hash[:size], nil
+}
+
+// RequiredSymbols returns the number of symbols of the source needed for size
+// bytes of entropy.
+func (u *UserEntropy) RequiredSymbols(size int) int {
+	return int(math.Ceil((float64(size*8) - 0.5) / math.Log2(float64(len(alphabets[u.Source])))))
+}
+
+// Mix hashes the entropy together with the same number of bytes read from rand,
+// so the result is at least as unpredictable as the better of the two.
+func Mix(entropy []byte, rand io.Reader) ([]byte, error) {
+	if len(entropy) > sha256.Size {
+		return nil, fmt.Errorf("invalid entropy size: %d bytes", len(entropy))
+	}
+	random := make([]byte, len(entropy))
+	defer clear(random)
+	if _, err := io.ReadFull(rand, random); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(entropy)
+	h.Write(random)
+	return h.Sum(nil)[:len(entropy)], nil
+}
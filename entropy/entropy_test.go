@@ -0,0 +1,174 @@
+package entropy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestReadAndEntropy(t *testing.T) {
+	t.Run("hex_exact", func(t *testing.T) {
+		u, err := Read(Hex, strings.Repeat("00", 16)+"\n"+strings.Repeat("FF", 16))
+		require.NoError(t, err)
+		assert.Equal(t, 256.0, u.Bits)
+
+		data, err := u.Entropy(32)
+		require.NoError(t, err)
+		assert.Equal(t, append(make([]byte, 16), bytes.Repeat([]byte{0xff}, 16)...), data)
+	})
+
+	t.Run("coins_exact", func(t *testing.T) {
+		u, err := Read(Coins, strings.Repeat("h t ", 64))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("10", 64), u.Symbols)
+
+		data, err := u.Entropy(16)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{0xaa}, 16), data)
+	})
+
+	t.Run("dice_hashed", func(t *testing.T) {
+		rolls := strings.Repeat("123456", 16) + "123"
+		u, err := Read(Dice, rolls)
+		require.NoError(t, err)
+		assert.Equal(t, 99, u.RequiredSymbols(32))
+
+		data, err := u.Entropy(32)
+		require.NoError(t, err)
+		hash := sha256.Sum256([]byte(rolls))
+		assert.Equal(t, hash[:], data)
+	})
+
+	t.Run("not_enough_entropy", func(t *testing.T) {
+		u, err := Read(Dice, strings.Repeat("1", 98))
+		require.NoError(t, err)
+		_, err = u.Entropy(32)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not enough entropy: 98 dice symbols provide 253 bits, 256 bits are required (99 symbols)")
+	})
+
+	t.Run("invalid_symbol", func(t *testing.T) {
+		_, err := Read(Dice, "1234567")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dice input '7' at position 6")
+	})
+}
+
+func TestBiasWarnings(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var fair strings.Builder
+	for i := 0; i < 99; i++ {
+		fair.WriteByte("123456"[rng.Intn(6)])
+	}
+	u, err := Read(Dice, fair.String())
+	require.NoError(t, err)
+	assert.Empty(t, u.Warnings)
+
+	u, err = Read(Dice, strings.Repeat("1", 50)+strings.Repeat("23456", 10))
+	require.NoError(t, err)
+	require.Len(t, u.Warnings, 2)
+	assert.Contains(t, u.Warnings[0], "frequencies are very uneven")
+	assert.Contains(t, u.Warnings[1], "repeats 50 times in a row")
+
+	u, err = Read(Coins, strings.Repeat("h", 128))
+	require.NoError(t, err)
+	assert.Contains(t, u.Warnings, "symbol 0 never appears in 128 symbols")
+}
+
+func TestMix(t *testing.T) {
+	user := bytes.Repeat([]byte{0x01}, 32)
+	random := bytes.Repeat([]byte{0x02}, 32)
+
+	mixed, err := Mix(user, bytes.NewReader(random))
+	require.NoError(t, err)
+	expected := sha256.Sum256(append(user, random...))
+	assert.Equal(t, expected[:], mixed)
+
+	_, err = Mix(user, bytes.NewReader(random[:10]))
+	require.Error(t, err)
+}
+
+func TestAudit(t *testing.T) {
+	audit, err := Audit(make([]byte, 32))
+	require.NoError(t, err)
+	require.Len(t, audit, 24)
+	assert.Equal(t, WordBits{Position: 1, Bits: "00000000000", Index: 0, Word: "abandon"}, audit[0])
+
+	last := audit[23]
+	assert.Equal(t, "art", last.Word)
+	assert.Equal(t, 8, last.ChecksumBits)
+	assert.Equal(t, "00001100110", last.Bits)
+	assert.Equal(t, 102, last.Index)
+}
+
+func TestFinalWords(t *testing.T) {
+	testCases := []struct {
+		name       string
+		words      int
+		candidates int
+		includes   string
+	}{
+		{name: "12_words", words: 11, candidates: 128, includes: "about"},
+		{name: "24_words", words: 23, candidates: 8, includes: "art"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			phrase := strings.TrimSpace(strings.Repeat("abandon ", tc.words))
+			words, err := FinalWords(phrase)
+			require.NoError(t, err)
+			assert.Len(t, words, tc.candidates)
+			assert.Contains(t, words, tc.includes)
+			for _, word := range words {
+				assert.True(t, bip39.IsMnemonicValid(phrase+" "+word), word)
+			}
+		})
+	}
+
+	_, err := FinalWords("abandon abandon")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "phrase must contain 11, 14, 17, 20 or 23 words, got 2")
+}
+
+func TestDRBG(t *testing.T) {
+	// First HMAC_DRBG SHA-256 vector of NIST CAVP without prediction
+	// resistance or reseeding: the output of the second generate call
+	seed, err := hex.DecodeString("ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	require.NoError(t, err)
+	nonce, err := hex.DecodeString("659ba96c601dc69fc902940805ec0ca8")
+	require.NoError(t, err)
+
+	drbg := NewDRBG(seed, nonce)
+	out := make([]byte, 128)
+	_, err = drbg.Read(out)
+	require.NoError(t, err)
+	_, err = drbg.Read(out)
+	require.NoError(t, err)
+	assert.Equal(t, "e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc107694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8", hex.EncodeToString(out))
+}
+
+func TestMixedReader(t *testing.T) {
+	random := bytes.Repeat([]byte{0x02}, 32)
+	reader, err := MixedReader(bytes.NewReader(random), []byte("extra"))
+	require.NoError(t, err)
+
+	out := make([]byte, 64)
+	_, err = io.ReadFull(reader, out)
+	require.NoError(t, err)
+
+	expected := make([]byte, 64)
+	_, err = NewDRBG(random, []byte("extra")).Read(expected)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	_, err = MixedReader(bytes.NewReader(random[:31]), nil)
+	require.Error(t, err)
+}
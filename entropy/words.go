@@ -0,0 +1,110 @@
+package entropy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const bitsPerWord = 11
+
+// WordBits shows how a group of 11 bits of a mnemonic maps to a word.
+type WordBits struct {
+	// Position is the 1-based position of the word in the mnemonic
+	Position int `json:"position"`
+	// Bits is the binary form of the word index
+	Bits string `json:"bits"`
+	// ChecksumBits is the number of trailing bits that are part of the
+	// checksum instead of the entropy, only non-zero for the last word
+	ChecksumBits int    `json:"checksum_bits,omitempty"`
+	Index        int    `json:"index"`
+	Word         string `json:"word"`
+}
+
+// Audit returns the mapping from the bits of the entropy, followed by its
+// checksum, to the words of its BIP39 mnemonic.
+func Audit(entropy []byte) ([]WordBits, error) {
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(mnemonic)
+	bits := bitString(entropy) + checksumBits(entropy)
+
+	result := make([]WordBits, len(words))
+	for i, word := range words {
+		index, _ := bip39.GetWordIndex(word)
+		result[i] = WordBits{
+			Position: i + 1,
+			Bits:     bits[i*bitsPerWord : (i+1)*bitsPerWord],
+			Index:    index,
+			Word:     word,
+		}
+	}
+	result[len(result)-1].ChecksumBits = len(entropy) / 4
+	return result, nil
+}
+
+// FinalWords returns all the words that complete the phrase into a valid
+// BIP39 mnemonic. The phrase must have one word less than a valid mnemonic,
+// like 11 or 23 words, and the last word then holds the remaining entropy
+// bits followed by the checksum.
+func FinalWords(phrase string) ([]string, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	total := len(words) + 1
+	if total < 12 || total > 24 || total%3 != 0 {
+		return nil, fmt.Errorf("phrase must contain 11, 14, 17, 20 or 23 words, got %d", len(words))
+	}
+
+	var bits strings.Builder
+	for _, word := range words {
+		index, ok := bip39.GetWordIndex(word)
+		if !ok {
+			return nil, fmt.Errorf("invalid word in phrase: %s", word)
+		}
+		fmt.Fprintf(&bits, "%011b", index)
+	}
+
+	checksumLength := total / 3
+	freeBits := bitsPerWord - checksumLength
+	wordList := bip39.GetWordList()
+	candidates := make([]string, 0, 1<<freeBits)
+	for free := 0; free < 1<<freeBits; free++ {
+		entropy := bitsToBytes(bits.String() + fmt.Sprintf("%0*b", freeBits, free))
+		checksum := checksumBits(entropy)
+		index, err := strconv.ParseUint(fmt.Sprintf("%0*b%s", freeBits, free, checksum), 2, bitsPerWord)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, wordList[index])
+	}
+	return candidates, nil
+}
+
+// checksumBits returns the BIP39 checksum bits of the entropy, one bit for
+// every 32 bits of entropy.
+func checksumBits(entropy []byte) string {
+	hash := sha256.Sum256(entropy)
+	return bitString(hash[:])[:len(entropy)/4]
+}
+
+func bitString(data []byte) string {
+	var b strings.Builder
+	for _, d := range data {
+		fmt.Fprintf(&b, "%08b", d)
+	}
+	return b.String()
+}
+
+func bitsToBytes(bits string) []byte {
+	data := make([]byte, len(bits)/8)
+	for i, c := range bits {
+		if c == '1' {
+			data[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return data
+}
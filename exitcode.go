@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/shards"
+)
+
+// Exit codes of the CLI, so that scripts can tell failures apart without
+// parsing the error message.
+const (
+	exitOK                 = 0
+	exitError              = 1
+	exitUsage              = 2
+	exitInvalidShare       = 3
+	exitInsufficientShares = 4
+	exitMixedSets          = 5
+	exitSecretMismatch     = 6
+	exitNoGo               = 7
+)
+
+// usageError is a command line that does not name a known command, or whose
+// options cannot be parsed.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// exitCode returns the exit code of the CLI for the error returned by RunCLI.
+func exitCode(err error) int {
+	var (
+		checksum     *model.ErrChecksumMismatch
+		invalidWord  *model.ErrInvalidWord
+		parse        *shards.ParseError
+		insufficient *model.ErrInsufficientShares
+		mixed        *model.ErrMixedSets
+		mismatch     *model.ErrSecretMismatch
+		usage        usageError
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errNoGo):
+		return exitNoGo
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &insufficient):
+		return exitInsufficientShares
+	case errors.As(err, &mixed):
+		return exitMixedSets
+	case errors.As(err, &mismatch):
+		return exitSecretMismatch
+	case errors.As(err, &checksum), errors.As(err, &invalidWord), errors.As(err, &parse), errors.Is(err, shards.ErrNoShares), errors.Is(err, errInvalidShare):
+		return exitInvalidShare
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/shards"
+)
+
+// errInvalidShare is returned by inspect once it reported a share that fails
+// one of its checksums.
+var errInvalidShare = errors.New("the share fails its checksums")
+
+// shareInfo is what inspect tells about a share, which is also its JSON
+// result.
+type shareInfo struct {
+	Identifier    string `json:"identifier"`
+	Label         string `json:"label,omitempty"`
+	FormatVersion int    `json:"format_version"`
+	// XCoordinate is the Shamir x coordinate of the share, which is also its
+	// number in a numbered share set
+	XCoordinate int `json:"x_coordinate"`
+	Total       int `json:"total,omitempty"`
+	Words       int `json:"words"`
+	// PayloadLength is the length of the split payload: the entropy of a
+	// BIP-39 secret, or the envelope of any other type, which holds its
+	// secret padded to a length the words can encode. The length of the
+	// secret itself is only known once the shares are combined.
+	PayloadLength int `json:"payload_length"`
+	// BIP39Checksum and ShareChecksum are whether the checksum of each BIP-39
+	// phrase of the share and its check byte are valid, which are checked
+	// separately
+	BIP39Checksum bool `json:"bip39_checksum"`
+	ShareChecksum bool `json:"share_checksum"`
+	// SetID and Threshold come from the manifest of the share set, if known
+	SetID     string `json:"set_id,omitempty"`
+	Threshold int    `json:"threshold,omitempty"`
+	Manifest  string `json:"manifest,omitempty"`
+}
+
+// inspectResult is the JSON result of inspect.
+type inspectResult struct {
+	Shares []shareInfo `json:"shares"`
+}
+
+// readInspectedShares reads the shares given to inspect: the share file at the
+// single argument, the text of a share given as the arguments, or the shares
+// piped to stdin. It also returns the path of the manifest next to the file,
+// or in the same directory, if there is one. The checksums of the shares are
+// not checked, so that inspect reports them.
+func readInspectedShares(args []string, indexBase int) ([]model.MnemonicShare, string, error) {
+	switch {
+	case len(args) == 1 && !strings.ContainsAny(strings.TrimSpace(args[0]), " \t\n"):
+		reader := &shards.FileReader{IndexBase: indexBase, SkipChecksums: true}
+		shares, err := reader.Read(args[0])
+		if err != nil {
+			return nil, "", err
+		}
+		info, err := os.Stat(args[0])
+		if err != nil {
+			return nil, "", err
+		}
+		candidates := []string{shards.ManifestPath(args[0], info.IsDir())}
+		if !info.IsDir() {
+			candidates = append(candidates, filepath.Join(filepath.Dir(args[0]), model.ManifestFileName))
+		}
+		for _, manifest := range candidates {
+			if _, err := os.Stat(manifest); err == nil {
+				return shares, manifest, nil
+			}
+		}
+		return shares, "", nil
+	case len(args) > 0:
+		decoder := shards.NewDecoder(strings.NewReader(strings.Join(args, " ")))
+		decoder.IndexBase, decoder.SkipChecksums = indexBase, true
+		shares, err := decoder.Decode()
+		return shares, "", err
+	case stdinPiped():
+		decoder := shards.NewDecoder(os.Stdin)
+		decoder.IndexBase, decoder.SkipChecksums = indexBase, true
+		shares, err := decoder.Decode()
+		return shares, "", err
+	}
+	return nil, "", usageError("expected a share or a share file to inspect")
+}
+
+// inspectShare checks the share and describes it, along with what the manifest
+// of its share set tells about it, if known. The shares are never combined.
+func inspectShare(share model.MnemonicShare, manifest *model.Manifest, manifestPath string) (shareInfo, error) {
+	id := fmt.Sprintf("%04x", share.Identifier)
+	bip39Valid, checkByteValid, err := share.Checksums()
+	if err != nil {
+		return shareInfo{}, err
+	}
+
+	words := len(strings.Fields(share.Mnemonic))
+	info := shareInfo{
+		Identifier:    id,
+		Label:         share.Label,
+		FormatVersion: share.FormatVersion(),
+		XCoordinate:   int(share.Identifier[0]),
+		Total:         share.Total,
+		Words:         words,
+		// each 3 words encode 4 bytes of the share data, which is as long
+		// as the payload
+		PayloadLength: words / 3 * 4,
+		BIP39Checksum: bip39Valid,
+		ShareChecksum: checkByteValid,
+	}
+	if manifest != nil {
+		if !slices.Contains(manifest.Shares, id) {
+			return shareInfo{}, &model.ErrMixedSets{ShareID: id, SetID: manifest.SetID}
+		}
+		info.SetID, info.Threshold, info.Manifest = manifest.SetID, manifest.Threshold, manifestPath
+		info.Total = manifest.Total
+	}
+	return info, nil
+}
+
+// printShareInfo prints what inspect found about a share.
+func printShareInfo(messages io.Writer, share model.MnemonicShare, info shareInfo) {
+	if name := share.Name(); strings.Contains(name, info.Identifier) {
+		fmt.Fprintln(messages, name)
+	} else {
+		fmt.Fprintf(messages, "%s (0x%s)\n", name, info.Identifier)
+	}
+	format := "numbered"
+	if info.FormatVersion == 1 {
+		format = "legacy"
+	}
+	fmt.Fprintf(messages, "Format version: %d (%s)\n", info.FormatVersion, format)
+	fmt.Fprintf(messages, "X coordinate: %d\n", info.XCoordinate)
+	fmt.Fprintf(messages, "Words: %d\n", info.Words)
+	fmt.Fprintf(messages, "Payload length: %d bytes\n", info.PayloadLength)
+	fmt.Fprintf(messages, "BIP-39 checksum: %s\n", validity(info.BIP39Checksum))
+	fmt.Fprintf(messages, "Share checksum: %s (0x%02x)\n", validity(info.ShareChecksum), share.Identifier[len(share.Identifier)-1])
+	if info.SetID == "" {
+		fmt.Fprintln(messages, "Set ID: unknown, no manifest found")
+		if info.Total > 0 {
+			fmt.Fprintf(messages, "Threshold: unknown, %d shares in total\n", info.Total)
+		}
+		return
+	}
+	fmt.Fprintf(messages, "Set ID: %s (from %s)\n", info.SetID, info.Manifest)
+	fmt.Fprintf(messages, "Threshold: %d of %d\n", info.Threshold, info.Total)
+	fmt.Fprintln(messages, setMembershipNote)
+}
+
+// setMembershipNote is printed with the share set found in a manifest, as a
+// share is only matched to the set by its identifier.
+const setMembershipNote = "Note: shares are matched to the set by their identifier only, which a share of another split has 1 time in 256; recover checks the secret against the manifest."
+
+// validity returns the word printed by inspect for the result of a check.
+func validity(valid bool) string {
+	if valid {
+		return "valid"
+	}
+	return "invalid"
+}
@@ -0,0 +1,78 @@
+package model
+
+import "fmt"
+
+// ErrChecksumMismatch is returned for a share whose check byte does not match
+// its words, which usually means that a word or the identifier was copied
+// wrong.
+type ErrChecksumMismatch struct {
+	// ShareID is the hex identifier of the share
+	ShareID  string
+	Expected byte
+	Got      byte
+}
+
+func (e *ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("invalid checksum on share %s (expected: %02x, got: %02x)", e.ShareID, e.Expected, e.Got)
+}
+
+// ErrInvalidWord is returned for a word that is not in the wordlist.
+type ErrInvalidWord struct {
+	// Position is the 1-based position of the word in its phrase, or 0 if it
+	// is not known
+	Position int
+	// Word is the invalid word as it was written. It is left empty when the
+	// word may be a typo of a word of a secret.
+	Word string
+}
+
+func (e *ErrInvalidWord) Error() string {
+	switch {
+	case e.Position == 0:
+		return fmt.Sprintf("invalid word: %s", e.Word)
+	case e.Word == "":
+		return fmt.Sprintf("invalid word %d", e.Position)
+	}
+	return fmt.Sprintf("invalid word %d: %s", e.Position, e.Word)
+}
+
+// ErrInsufficientShares is returned when there are not enough shares to
+// recover a secret.
+type ErrInsufficientShares struct {
+	Have int
+	Need int
+	// SetID is the share set the threshold comes from, if known
+	SetID string
+}
+
+func (e *ErrInsufficientShares) Error() string {
+	if e.SetID != "" {
+		return fmt.Sprintf("share set %s requires %d shares to recover, got %d", e.SetID, e.Need, e.Have)
+	}
+	return fmt.Sprintf("at least %d shares are required to recover the secret, got %d", e.Need, e.Have)
+}
+
+// ErrMixedSets is returned when shares from different splits are combined.
+type ErrMixedSets struct {
+	// ShareID is the hex identifier of a share that is not part of the set
+	ShareID string
+	// SetID is the share set the other shares belong to, if known
+	SetID string
+}
+
+func (e *ErrMixedSets) Error() string {
+	if e.SetID != "" {
+		return fmt.Sprintf("share %s is not part of share set %s", e.ShareID, e.SetID)
+	}
+	return fmt.Sprintf("share %s is not part of the same share set as the other shares", e.ShareID)
+}
+
+// ErrSecretMismatch is returned when the recovered secret is not the one the
+// manifest of its share set was created for.
+type ErrSecretMismatch struct {
+	SetID string
+}
+
+func (e *ErrSecretMismatch) Error() string {
+	return fmt.Sprintf("recovered secret does not match the manifest of share set %s", e.SetID)
+}
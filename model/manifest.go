@@ -0,0 +1,179 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	// ManifestVersion is the version of the manifest format written by split.
+	ManifestVersion = 1
+	// ManifestFileName is the name of the manifest file written next to the
+	// share files when splitting into a directory.
+	ManifestFileName = "manifest.json"
+
+	setIDLength = 8
+	saltLength  = 16
+)
+
+// Manifest describes a set of shares created by a single split. It holds no
+// secret information, only what is needed to check that a set of shares
+// belongs together and that the recovered secret is the one that was split.
+type Manifest struct {
+	Version int `json:"version"`
+	// SetID is a random identifier of the split
+	SetID     string `json:"set_id"`
+	Threshold int    `json:"threshold"`
+	Total     int    `json:"total"`
+	// Shares lists the hex identifiers of all shares in the set
+	Shares []string `json:"shares"`
+	// Labels lists the custodian labels of the shares, in the same order as
+	// Shares, if they were labeled
+	Labels []string `json:"labels,omitempty"`
+	// SecretType is the type of the secret that was split
+	SecretType string `json:"secret_type"`
+	// Fingerprint is the BIP32 master key fingerprint of the secret, if any
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Salt and SecretHash hold a salted HMAC-SHA256 of the split payload
+	Salt       string `json:"salt"`
+	SecretHash string `json:"secret_hash"`
+}
+
+// NewManifest creates the manifest for the shares of payload, with a random set
+// ID and salt.
+func NewManifest(payload []byte, shares []MnemonicShare, k int) (*Manifest, error) {
+	random := make([]byte, setIDLength+saltLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate manifest salt: %w", err)
+	}
+	salt := random[setIDLength:]
+
+	ids := make([]string, len(shares))
+	labels := make([]string, len(shares))
+	labeled := false
+	for i, share := range shares {
+		ids[i] = fmt.Sprintf("%04x", share.Identifier)
+		labels[i] = share.Label
+		labeled = labeled || share.Label != ""
+	}
+	if !labeled {
+		labels = nil
+	}
+	return &Manifest{
+		Version:    ManifestVersion,
+		SetID:      hex.EncodeToString(random[:setIDLength]),
+		Threshold:  k,
+		Total:      len(shares),
+		Shares:     ids,
+		Labels:     labels,
+		Salt:       hex.EncodeToString(salt),
+		SecretHash: hex.EncodeToString(secretHash(salt, payload)),
+	}, nil
+}
+
+// ParseManifest parses and validates a JSON manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version: %d", m.Version)
+	}
+	if m.Threshold < 2 || m.Total < m.Threshold || len(m.Shares) != m.Total {
+		return nil, fmt.Errorf("invalid manifest threshold %d for %d shares", m.Threshold, len(m.Shares))
+	}
+	if len(m.Labels) != 0 && len(m.Labels) != m.Total {
+		return nil, fmt.Errorf("invalid manifest with %d labels for %d shares", len(m.Labels), m.Total)
+	}
+	if _, err := hex.DecodeString(m.Salt); err != nil {
+		return nil, fmt.Errorf("invalid manifest salt: %w", err)
+	}
+	if hash, err := hex.DecodeString(m.SecretHash); err != nil || len(hash) != sha256.Size {
+		return nil, fmt.Errorf("invalid manifest secret hash")
+	}
+	return &m, nil
+}
+
+// CheckShares checks that the shares are part of the set described by the
+// manifest and that there are enough of them to recover the secret. Shares are
+// only matched by their identifier, which is the share number and an 8-bit
+// check byte, so a share of another split with the same number is taken as
+// part of the set 1 time in 256. A share that is not accepted is never part of
+// the set, but only VerifySecret tells for sure that the shares were.
+func (m *Manifest) CheckShares(shares []MnemonicShare) error {
+	for _, share := range shares {
+		if id := fmt.Sprintf("%04x", share.Identifier); !slices.Contains(m.Shares, id) {
+			return &ErrMixedSets{ShareID: id, SetID: m.SetID}
+		}
+	}
+	if len(shares) < m.Threshold {
+		return &ErrInsufficientShares{Have: len(shares), Need: m.Threshold, SetID: m.SetID}
+	}
+	return nil
+}
+
+// Custodians returns the names of the shares of the set that are among the
+// given shares and of those that are missing. Shares are named by their label,
+// or if the set is not labeled by their number when the given shares are
+// numbered, and by their identifier otherwise, since legacy shares have random
+// numbers.
+func (m *Manifest) Custodians(shares []MnemonicShare) (present, missing []string) {
+	inSet := func(share MnemonicShare) bool {
+		return slices.Contains(m.Shares, fmt.Sprintf("%04x", share.Identifier))
+	}
+	numbered := slices.ContainsFunc(shares, func(share MnemonicShare) bool {
+		return share.FormatVersion() == 2 && inSet(share)
+	})
+
+	for i, id := range m.Shares {
+		name := fmt.Sprintf("Share 0x%s", id)
+		if identifier, err := hex.DecodeString(id); err == nil && numbered {
+			share := MnemonicShare{Identifier: identifier, Total: m.Total}
+			if number := share.Number(); number > 0 {
+				name = fmt.Sprintf("Share %d of %d", number, m.Total)
+			}
+		}
+		if len(m.Labels) == m.Total && m.Labels[i] != "" {
+			name = m.Labels[i]
+		}
+
+		found := slices.ContainsFunc(shares, func(share MnemonicShare) bool {
+			return fmt.Sprintf("%04x", share.Identifier) == id
+		})
+		if found {
+			present = append(present, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return present, missing
+}
+
+// VerifySecret checks that payload is the secret the manifest was created for.
+func (m *Manifest) VerifySecret(payload []byte) error {
+	salt, err := hex.DecodeString(m.Salt)
+	if err != nil {
+		return fmt.Errorf("invalid manifest salt: %w", err)
+	}
+	expected, err := hex.DecodeString(m.SecretHash)
+	if err != nil {
+		return fmt.Errorf("invalid manifest secret hash: %w", err)
+	}
+	if !hmac.Equal(expected, secretHash(salt, payload)) {
+		return &ErrSecretMismatch{SetID: m.SetID}
+	}
+	return nil
+}
+
+// secretHash returns the HMAC-SHA256 of the payload keyed with the salt.
+func secretHash(salt, payload []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
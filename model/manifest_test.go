@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	payload := []byte("0123456789abcdef0123456789abcdef")
+	shares := []MnemonicShare{
+		{Identifier: []byte{0x3a, 0x11}},
+		{Identifier: []byte{0x7f, 0x02}},
+		{Identifier: []byte{0xc4, 0x9e}},
+	}
+
+	manifest, err := NewManifest(payload, shares, 2)
+	require.NoError(t, err)
+	assert.Len(t, manifest.SetID, 16)
+	assert.Equal(t, []string{"3a11", "7f02", "c49e"}, manifest.Shares)
+
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	parsed, err := ParseManifest(data)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, parsed)
+
+	t.Run("verify_secret", func(t *testing.T) {
+		require.NoError(t, parsed.VerifySecret(payload))
+
+		err := parsed.VerifySecret([]byte("0123456789abcdef0123456789abcdeX"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match the manifest")
+		var mismatch *ErrSecretMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, manifest.SetID, mismatch.SetID)
+	})
+
+	t.Run("check_shares", func(t *testing.T) {
+		require.NoError(t, parsed.CheckShares(shares[1:]))
+
+		err := parsed.CheckShares(shares[:1])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires 2 shares to recover, got 1")
+		var insufficient *ErrInsufficientShares
+		require.ErrorAs(t, err, &insufficient)
+		assert.Equal(t, ErrInsufficientShares{Have: 1, Need: 2, SetID: manifest.SetID}, *insufficient)
+
+		err = parsed.CheckShares([]MnemonicShare{shares[0], {Identifier: []byte{0x3a, 0x12}}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "share 3a12 is not part of share set")
+		var mixed *ErrMixedSets
+		require.ErrorAs(t, err, &mixed)
+		assert.Equal(t, "3a12", mixed.ShareID)
+	})
+
+	t.Run("custodians", func(t *testing.T) {
+		// legacy shares have random numbers, so they are named by identifier
+		present, missing := parsed.Custodians(shares[1:])
+		assert.Equal(t, []string{"Share 0x7f02", "Share 0xc49e"}, present)
+		assert.Equal(t, []string{"Share 0x3a11"}, missing)
+
+		numbered := []MnemonicShare{
+			{Identifier: []byte{0x01, 0x5b}, Total: 3},
+			{Identifier: []byte{0x02, 0xe0}, Total: 3},
+			{Identifier: []byte{0x03, 0x47}, Total: 3},
+		}
+		manifest, err := NewManifest(payload, numbered, 2)
+		require.NoError(t, err)
+		present, missing = manifest.Custodians(numbered[1:])
+		assert.Equal(t, []string{"Share 2 of 3", "Share 3 of 3"}, present)
+		assert.Equal(t, []string{"Share 1 of 3"}, missing)
+
+		labeled := slices.Clone(shares)
+		labeled[0].Label, labeled[1].Label, labeled[2].Label = "Alice", "Bob", "Bank box"
+		manifest, err = NewManifest(payload, labeled, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alice", "Bob", "Bank box"}, manifest.Labels)
+
+		present, missing = manifest.Custodians(labeled[:2])
+		assert.Equal(t, []string{"Alice", "Bob"}, present)
+		assert.Equal(t, []string{"Bank box"}, missing)
+	})
+
+	t.Run("salt_is_random", func(t *testing.T) {
+		other, err := NewManifest(payload, shares, 2)
+		require.NoError(t, err)
+		assert.NotEqual(t, manifest.SetID, other.SetID)
+		assert.NotEqual(t, manifest.SecretHash, other.SecretHash)
+	})
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	testCases := []struct {
+		name, json, errMsg string
+	}{
+		{
+			name:   "not_json",
+			json:   "3a11: word word",
+			errMsg: "invalid manifest",
+		},
+		{
+			name:   "unknown_version",
+			json:   `{"version": 2}`,
+			errMsg: "unsupported manifest version: 2",
+		},
+		{
+			name:   "missing_shares",
+			json:   `{"version": 1, "threshold": 2, "total": 3, "shares": ["3a11"]}`,
+			errMsg: "invalid manifest threshold",
+		},
+		{
+			name:   "missing_hash",
+			json:   `{"version": 1, "threshold": 2, "total": 2, "shares": ["3a11", "7f02"], "salt": "00"}`,
+			errMsg: "invalid manifest secret hash",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tc.json))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
@@ -2,11 +2,14 @@ package model
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
-	"github.com/hashicorp/vault/shamir"
-	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/shamir"
 )
 
 // MnemonicShare represents a single share of a split mnemonic phrase.
@@ -14,8 +17,120 @@ import (
 type MnemonicShare struct {
 	// Identifier contains the Shamir share ID bytes plus a checksum byte
 	Identifier []byte
-	// Mnemonic is a BIP39 mnemonic phrase representing the share data
+	// Mnemonic is a BIP39 mnemonic phrase representing the share data. As a
+	// string, it cannot be wiped, and stays in memory until it is reused.
 	Mnemonic string
+	// Total is the number of shares in the set, or 0 if unknown. Shares with a
+	// total are displayed as "Share x of total".
+	Total int
+	// Label names the custodian meant to hold the share, if any. It is not part
+	// of the share data and is stored as a comment next to it.
+	Label string
+}
+
+// LabelComment is the prefix of the comment line that holds the label of the
+// share on the following line in share files.
+const LabelComment = "# Custodian:"
+
+// numberedSharePattern matches shares in the "Share 3 of 5 (0x03c4): words"
+// format.
+var numberedSharePattern = regexp.MustCompile(`(?i)^share\s+(\d+)\s+of\s+(\d+)\s*\(\s*((?:0x)?[0-9a-f]+)\s*\)\s*:?\s*(.*)$`)
+
+// ParseMnemonicShare parses a share in the format returned by String, either
+// numbered ("Share 3 of 5 (0x03c4): words...") or in the legacy format with
+// only the identifier ("0x5954: words...").
+func ParseMnemonicShare(line string) (MnemonicShare, error) {
+	return parseShare(line, NewMnemonicShare)
+}
+
+// ParseUncheckedShare parses a share like ParseMnemonicShare, but creates it
+// like NewUncheckedShare, without checking its checksums.
+func ParseUncheckedShare(line string) (MnemonicShare, error) {
+	return parseShare(line, NewUncheckedShare)
+}
+
+// parseShare parses a share in the format returned by String, and creates it
+// with newShare.
+func parseShare(line string, newShare func(identifier, mnemonic string) (MnemonicShare, error)) (MnemonicShare, error) {
+	line = strings.TrimSpace(line)
+	match := numberedSharePattern.FindStringSubmatch(line)
+	if match == nil {
+		identifier, mnemonic, _ := strings.Cut(line, " ")
+		return newShare(identifier, strings.Join(strings.Fields(mnemonic), " "))
+	}
+
+	share, err := newShare(match[3], strings.Join(strings.Fields(match[4]), " "))
+	if err != nil {
+		return MnemonicShare{}, err
+	}
+	number, _ := strconv.Atoi(match[1])
+	total, _ := strconv.Atoi(match[2])
+	if err := share.setTotal(number, total); err != nil {
+		return MnemonicShare{}, err
+	}
+	return share, nil
+}
+
+// setTotal sets the total number of shares of the set, after checking that the
+// share is number of total.
+func (s *MnemonicShare) setTotal(number, total int) error {
+	if number < 1 || number > total || total > 255 {
+		return fmt.Errorf("invalid share number: %d of %d", number, total)
+	}
+	if s.Number() != number {
+		return fmt.Errorf("share number %d does not match identifier %04x", number, s.Identifier)
+	}
+	s.Total = total
+	return nil
+}
+
+// shareJSON is the JSON form of a share.
+type shareJSON struct {
+	// Number and Total are only set for shares with a known total
+	Number     int    `json:"number,omitempty"`
+	Total      int    `json:"total,omitempty"`
+	Identifier string `json:"identifier"`
+	Label      string `json:"label,omitempty"`
+	Mnemonic   string `json:"mnemonic"`
+}
+
+// MarshalJSON encodes the share as a JSON object with its number and total, if
+// known, its hex identifier, label and mnemonic.
+func (s MnemonicShare) MarshalJSON() ([]byte, error) {
+	share := shareJSON{
+		Identifier: fmt.Sprintf("%04x", s.Identifier),
+		Label:      s.Label,
+		Mnemonic:   s.Mnemonic,
+	}
+	if s.Total > 0 {
+		share.Number, share.Total = s.Number(), s.Total
+	}
+	return json.Marshal(share)
+}
+
+// UnmarshalJSON decodes a share in the format of MarshalJSON, and validates it
+// like ParseMnemonicShare. The number may be omitted.
+func (s *MnemonicShare) UnmarshalJSON(data []byte) error {
+	var decoded shareJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	share, err := NewMnemonicShare(decoded.Identifier, strings.Join(strings.Fields(decoded.Mnemonic), " "))
+	if err != nil {
+		return err
+	}
+	if decoded.Total > 0 {
+		number := decoded.Number
+		if number == 0 {
+			number = share.Number()
+		}
+		if err := share.setTotal(number, decoded.Total); err != nil {
+			return err
+		}
+	}
+	share.Label = decoded.Label
+	*s = share
+	return nil
 }
 
 // NewMnemonicShare creates a new share with the given identifier and mnemonic.
@@ -29,15 +144,15 @@ type MnemonicShare struct {
 // - The mnemonic is not a valid BIP39 mnemonic
 // - The checksum byte in the identifier is invalid
 func NewMnemonicShare(identifier, mnemonic string) (MnemonicShare, error) {
-	identifier = strings.TrimSpace(identifier)
-	identifier = strings.TrimSuffix(strings.TrimPrefix(identifier, "0x"), ":")
-	identifierBytes, err := hex.DecodeString(identifier)
+	identifierBytes, err := parseIdentifier(identifier)
 	if err != nil {
-		return MnemonicShare{}, fmt.Errorf("invalid identifier: %w", err)
+		return MnemonicShare{}, err
 	}
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return MnemonicShare{}, fmt.Errorf("invalid mnemonic")
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return MnemonicShare{}, fmt.Errorf("invalid mnemonic: %w", err)
 	}
+	clear(entropy)
 	share := MnemonicShare{
 		Identifier: identifierBytes,
 		Mnemonic:   mnemonic,
@@ -49,13 +164,45 @@ func NewMnemonicShare(identifier, mnemonic string) (MnemonicShare, error) {
 	return share, nil
 }
 
+// NewUncheckedShare creates a share like NewMnemonicShare, without checking
+// the BIP39 checksums of its mnemonic or its check byte, so that a damaged
+// share can still be inspected with Checksums. The words must still be in the
+// wordlist.
+func NewUncheckedShare(identifier, mnemonic string) (MnemonicShare, error) {
+	identifierBytes, err := parseIdentifier(identifier)
+	if err != nil {
+		return MnemonicShare{}, err
+	}
+	entropy, _, err := decodeMnemonic(mnemonic)
+	if err != nil {
+		return MnemonicShare{}, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	clear(entropy)
+	return MnemonicShare{Identifier: identifierBytes, Mnemonic: mnemonic}, nil
+}
+
+// parseIdentifier parses a hex share identifier, with an optional 0x prefix
+// and colon suffix.
+func parseIdentifier(identifier string) ([]byte, error) {
+	identifier = strings.TrimSpace(identifier)
+	identifier = strings.TrimSuffix(strings.TrimPrefix(identifier, "0x"), ":")
+	identifierBytes, err := hex.DecodeString(identifier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identifier: %w", err)
+	}
+	if len(identifierBytes) == 0 {
+		return nil, fmt.Errorf("invalid identifier: %s", identifier)
+	}
+	return identifierBytes, nil
+}
+
 // NewMnemonicShareFromShamir creates a MnemonicShare from raw Shamir share bytes.
 // The input bytes should contain the share data followed by the Shamir overhead bytes.
 //
 // The function:
 // 1. Splits the input into data and identifier parts
 // 2. Calculates and appends a checksum byte to the identifier
-// 3. Converts the data into a BIP39 mnemonic
+// 3. Converts the data into a BIP39 mnemonic (see EntropyToMnemonic)
 //
 // The checksum byte is calculated by XORing all bytes of the identifier
 // with all bytes of the share data.
@@ -67,7 +214,7 @@ func NewMnemonicShareFromShamir(share []byte) (MnemonicShare, error) {
 	identifier := share[len(share)-shamir.ShareOverhead:]
 	identifier = append(identifier, checksumByte(identifier, data))
 
-	shareMnemonic, err := bip39.NewMnemonic(data)
+	shareMnemonic, err := EntropyToMnemonic(data)
 	if err != nil {
 		return MnemonicShare{}, fmt.Errorf("failed to generate mnemonic for share: %w", err)
 	}
@@ -81,7 +228,7 @@ func NewMnemonicShareFromShamir(share []byte) (MnemonicShare, error) {
 // It validates the checksum byte before returning the converted bytes.
 //
 // The function:
-// 1. Extracts entropy from the BIP39 mnemonic
+// 1. Extracts entropy from the BIP39 mnemonic (see MnemonicToEntropy)
 // 2. Validates the checksum byte in the identifier
 // 3. Returns the concatenated entropy and identifier (without checksum)
 //
@@ -89,24 +236,118 @@ func NewMnemonicShareFromShamir(share []byte) (MnemonicShare, error) {
 // - The mnemonic is invalid
 // - The checksum byte validation fails
 func (s MnemonicShare) ToShamir() ([]byte, error) {
-	entropy, err := bip39.EntropyFromMnemonic(s.Mnemonic)
+	entropy, err := MnemonicToEntropy(s.Mnemonic)
 	if err != nil {
 		return nil, fmt.Errorf("invalid mnemonic: %w", err)
 	}
 	checksum := s.Identifier[len(s.Identifier)-1]
 	onlyID := s.Identifier[:len(s.Identifier)-1]
 	if expectedChecksum := checksumByte(onlyID, entropy); expectedChecksum != checksum {
-		return nil, fmt.Errorf("invalid checksum on share %04x (expected: %02x, got: %02x)", s.Identifier, expectedChecksum, checksum)
+		return nil, &ErrChecksumMismatch{ShareID: fmt.Sprintf("%04x", s.Identifier), Expected: expectedChecksum, Got: checksum}
 	}
 	return append(entropy, onlyID...), nil
 }
 
+// Checksums checks the share like ToShamir, but checks the BIP39 checksum of
+// each phrase of its mnemonic and its check byte separately, so that both are
+// checked even if the other one fails. The check byte is checked against the
+// entropy of the words, even if their BIP39 checksum is not valid.
+func (s MnemonicShare) Checksums() (bip39Valid, checkByteValid bool, err error) {
+	entropy, bip39Valid, err := decodeMnemonic(s.Mnemonic)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	defer clear(entropy)
+	onlyID := s.Identifier[:len(s.Identifier)-1]
+	return bip39Valid, checksumByte(onlyID, entropy) == s.Identifier[len(s.Identifier)-1], nil
+}
+
+// Number returns the number of the share, which is its Shamir x coordinate.
+// Shares from the same split are numbered from 1 to the total number of shares,
+// except for legacy shares that have random numbers.
+func (s MnemonicShare) Number() int {
+	if len(s.Identifier) != 1+shamir.ShareOverhead {
+		return 0
+	}
+	return int(s.Identifier[0])
+}
+
+// FormatVersion returns the version of the format of the share: 1 for legacy
+// shares, like "0x5954: words...", which have random numbers and no total, and
+// 2 for numbered shares, like "Share 3 of 5 (0x03c4): words...".
+func (s MnemonicShare) FormatVersion() int {
+	if s.Total > 0 {
+		return 2
+	}
+	return 1
+}
+
 // String returns a human-readable string representation of the share.
-// The string includes the identifier in hexadecimal and the mnemonic phrase.
+// The string includes the share number if the total is known, the identifier
+// in hexadecimal and the mnemonic phrase.
 func (s MnemonicShare) String() string {
+	if s.Total > 0 {
+		return fmt.Sprintf("Share %d of %d (0x%04x): %s", s.Number(), s.Total, s.Identifier, s.Mnemonic)
+	}
 	return fmt.Sprintf("0x%04x: %s", s.Identifier, s.Mnemonic)
 }
 
+// WrongWords compares the words of a copy of the share with its mnemonic, and
+// returns the 1-based positions of the words that differ, including the ones
+// missing from or added to the copy.
+func (s MnemonicShare) WrongWords(words []string) []int {
+	expected := strings.Fields(s.Mnemonic)
+	var wrong []int
+	for i := 0; i < max(len(expected), len(words)); i++ {
+		if i >= len(expected) || i >= len(words) || words[i] != expected[i] {
+			wrong = append(wrong, i+1)
+		}
+	}
+	return wrong
+}
+
+// Name returns a short human-readable name for the share: its label if it has
+// one, or its number or identifier otherwise.
+func (s MnemonicShare) Name() string {
+	switch {
+	case s.Label != "":
+		return s.Label
+	case s.Total > 0:
+		return fmt.Sprintf("Share %d of %d", s.Number(), s.Total)
+	default:
+		return fmt.Sprintf("Share 0x%04x", s.Identifier)
+	}
+}
+
+// FileName returns the name of the file the share is saved to, like
+// share_3_of_5.txt, or share_5954.txt if the total is unknown. The label of the
+// share is appended if it has one, like share_3_of_5_bank_box.txt.
+func (s MnemonicShare) FileName() string {
+	name := fmt.Sprintf("share_%04x", s.Identifier)
+	if s.Total > 0 {
+		name = fmt.Sprintf("share_%d_of_%d", s.Number(), s.Total)
+	}
+	if slug := labelSlug(s.Label); slug != "" {
+		name += "_" + slug
+	}
+	return name + ".txt"
+}
+
+// labelSlug converts a label to lowercase letters, digits and underscores, so
+// it can be used in a file name.
+func labelSlug(label string) string {
+	var slug strings.Builder
+	for _, word := range strings.FieldsFunc(strings.ToLower(label), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if slug.Len() > 0 {
+			slug.WriteByte('_')
+		}
+		slug.WriteString(word)
+	}
+	return slug.String()
+}
+
 // checksumByte calculates a checksum byte by XORing all bytes in the identifier
 // and data arrays. This provides a simple data integrity check for the share.
 func checksumByte(identifier, data []byte) byte {
@@ -2,6 +2,10 @@ package model
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,6 +33,16 @@ func TestMnemonicShare(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid mnemonic")
 	})
 
+	t.Run("create_invalid_word", func(t *testing.T) {
+		words := strings.Fields(mnemSh.Mnemonic)
+		words[4] = "bitcoin"
+		_, err := NewMnemonicShare(hex.EncodeToString(mnemSh.Identifier), strings.Join(words, " "))
+		var invalid *ErrInvalidWord
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, 5, invalid.Position)
+		assert.Empty(t, invalid.Word)
+	})
+
 	t.Run("shamir_conversion_roundtrip", func(t *testing.T) {
 		// Create a share from Shamir
 		entropy, err := bip39.NewEntropy(256)
@@ -74,6 +88,10 @@ func TestMnemonicShare(t *testing.T) {
 		_, err = share.ToShamir()
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid checksum on share")
+		var mismatch *ErrChecksumMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, "01ff", mismatch.ShareID)
+		assert.Equal(t, byte(0xff), mismatch.Got)
 	})
 }
 
@@ -111,3 +129,156 @@ func TestXorCheckByte(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMnemonicShare(t *testing.T) {
+	entropy, err := bip39.NewEntropy(256)
+	require.NoError(t, err)
+	share, err := NewMnemonicShareFromShamir(append(entropy, 0x03))
+	require.NoError(t, err)
+
+	t.Run("legacy_format", func(t *testing.T) {
+		assert.Equal(t, "0x03", share.String()[:4])
+		assert.Equal(t, 1, share.FormatVersion())
+		assert.Equal(t, fmt.Sprintf("share_%04x.txt", share.Identifier), share.FileName())
+
+		parsed, err := ParseMnemonicShare(share.String())
+		require.NoError(t, err)
+		assert.Equal(t, share, parsed)
+	})
+
+	t.Run("numbered_format", func(t *testing.T) {
+		numbered := share
+		numbered.Total = 5
+		assert.Equal(t, fmt.Sprintf("Share 3 of 5 (0x%04x): %s", share.Identifier, share.Mnemonic), numbered.String())
+		assert.Equal(t, "share_3_of_5.txt", numbered.FileName())
+		assert.Equal(t, 2, numbered.FormatVersion())
+
+		parsed, err := ParseMnemonicShare(numbered.String())
+		require.NoError(t, err)
+		assert.Equal(t, numbered, parsed)
+
+		parsed, err = ParseMnemonicShare(fmt.Sprintf("  share 3 of 5 (%x)   %s\n", share.Identifier, share.Mnemonic))
+		require.NoError(t, err)
+		assert.Equal(t, numbered, parsed)
+	})
+
+	t.Run("labeled", func(t *testing.T) {
+		labeled := share
+		labeled.Total = 5
+		assert.Equal(t, "Share 3 of 5", labeled.Name())
+
+		labeled.Label = "Bank box #2"
+		assert.Equal(t, "Bank box #2", labeled.Name())
+		assert.Equal(t, "share_3_of_5_bank_box_2.txt", labeled.FileName())
+	})
+
+	t.Run("number_mismatch", func(t *testing.T) {
+		_, err := ParseMnemonicShare(fmt.Sprintf("Share 2 of 5 (0x%04x): %s", share.Identifier, share.Mnemonic))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "share number 2 does not match identifier")
+	})
+
+	t.Run("number_above_total", func(t *testing.T) {
+		_, err := ParseMnemonicShare(fmt.Sprintf("Share 3 of 2 (0x%04x): %s", share.Identifier, share.Mnemonic))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid share number: 3 of 2")
+	})
+}
+
+func TestWrongWords(t *testing.T) {
+	share := MnemonicShare{Mnemonic: "drum wage genuine tourist slim hungry"}
+	words := strings.Fields(share.Mnemonic)
+	assert.Empty(t, share.WrongWords(words))
+
+	typo := slices.Clone(words)
+	typo[1], typo[4] = "wagon", "slam"
+	assert.Equal(t, []int{2, 5}, share.WrongWords(typo))
+	assert.Equal(t, []int{5, 6}, share.WrongWords(words[:4]))
+	assert.Equal(t, []int{7}, share.WrongWords(append(slices.Clone(words), "extra")))
+}
+
+func TestMnemonicShareJSON(t *testing.T) {
+	share, err := ParseMnemonicShare("Share 173 of 200 (0xade1): drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery")
+	require.NoError(t, err)
+	share.Label = "Bank box"
+
+	data, err := json.Marshal(share)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"number":173,"total":200,"identifier":"ade1","label":"Bank box","mnemonic":"drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"}`, string(data))
+
+	var decoded MnemonicShare
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, share, decoded)
+
+	t.Run("legacy", func(t *testing.T) {
+		legacy := share
+		legacy.Total, legacy.Label = 0, ""
+		data, err := json.Marshal(legacy)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "number")
+
+		var decoded MnemonicShare
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, legacy, decoded)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var decoded MnemonicShare
+		err := json.Unmarshal([]byte(`{"number":2,"total":200,"identifier":"ade1","mnemonic":"`+share.Mnemonic+`"}`), &decoded)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "share number 2 does not match identifier")
+
+		err = json.Unmarshal([]byte(`{"identifier":"ade2","mnemonic":"`+share.Mnemonic+`"}`), &decoded)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid checksum")
+	})
+}
+
+func TestChecksums(t *testing.T) {
+	mnemonic := "drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"
+	testCases := []struct {
+		name, line       string
+		bip39, checkByte bool
+	}{
+		{
+			name:      "valid",
+			line:      "0xade1: " + mnemonic,
+			bip39:     true,
+			checkByte: true,
+		},
+		{
+			name:  "check_byte",
+			line:  "Share 173 of 200 (0xade2): " + mnemonic,
+			bip39: true,
+		},
+		{
+			// loud only differs from lottery in the BIP39 checksum bits
+			name:      "bip39",
+			line:      "0xade1: " + strings.Replace(mnemonic, "lottery", "loud", 1),
+			checkByte: true,
+		},
+		{
+			name: "both",
+			line: "0xade1: " + strings.Replace(mnemonic, "drum wage", "wage drum", 1),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			share, err := ParseUncheckedShare(tc.line)
+			require.NoError(t, err)
+			bip39Valid, checkByteValid, err := share.Checksums()
+			require.NoError(t, err)
+			assert.Equal(t, tc.bip39, bip39Valid)
+			assert.Equal(t, tc.checkByte, checkByteValid)
+
+			_, err = ParseMnemonicShare(tc.line)
+			assert.Equal(t, tc.bip39 && tc.checkByte, err == nil)
+		})
+	}
+
+	_, err := NewUncheckedShare("0xade1", strings.Replace(mnemonic, "drum", "drun", 1))
+	var invalid *ErrInvalidWord
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, 1, invalid.Position)
+}
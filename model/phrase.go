@@ -0,0 +1,205 @@
+package model
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// phraseEntropy is the number of entropy bytes in a full 24-word phrase.
+	phraseEntropy = 32
+	// phraseWords is the number of words in a full 24-word phrase.
+	phraseWords = 24
+)
+
+// EncodableLength returns the smallest data length greater than or equal to n
+// that can be encoded by EntropyToMnemonic.
+func EncodableLength(n int) int {
+	if n <= 16 {
+		return 16
+	}
+	full, rest := n/phraseEntropy, n%phraseEntropy
+	switch {
+	case rest == 0:
+		return n
+	case rest <= 16:
+		return full*phraseEntropy + 16
+	default:
+		return full*phraseEntropy + (rest+3)/4*4
+	}
+}
+
+// EntropyToMnemonic encodes data of arbitrary encodable length as a sequence
+// of BIP39 phrases. The data is cut in chunks of 32 bytes, each becoming a
+// 24-word phrase, and the remainder (16 to 28 bytes) becomes a shorter final
+// phrase. Data of up to 32 bytes is therefore encoded as a single standard
+// BIP39 mnemonic.
+func EntropyToMnemonic(data []byte) (string, error) {
+	if EncodableLength(len(data)) != len(data) {
+		return "", fmt.Errorf("cannot encode %d bytes as a mnemonic", len(data))
+	}
+
+	phrases := make([]string, 0, (len(data)+phraseEntropy-1)/phraseEntropy)
+	for start := 0; start < len(data); start += phraseEntropy {
+		end := min(start+phraseEntropy, len(data))
+		phrase, err := bip39.NewMnemonic(data[start:end])
+		if err != nil {
+			return "", err
+		}
+		phrases = append(phrases, phrase)
+	}
+	return strings.Join(phrases, " "), nil
+}
+
+// MnemonicToEntropy is the inverse of EntropyToMnemonic. The words are read
+// in groups of 24, and each group, including a shorter final one, must be a
+// valid BIP39 mnemonic with its own checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if !IsWordCountValid(len(words)) {
+		return nil, fmt.Errorf("invalid number of words: %d", len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return nil, &ErrInvalidWord{Position: i + 1}
+		}
+	}
+
+	entropy := make([]byte, 0, len(words)/3*4)
+	for start := 0; start < len(words); start += phraseWords {
+		end := min(start+phraseWords, len(words))
+		chunk, err := bip39.EntropyFromMnemonic(strings.Join(words[start:end], " "))
+		if err != nil {
+			return nil, err
+		}
+		entropy = append(entropy, chunk...)
+	}
+	return entropy, nil
+}
+
+// IsMnemonicValid reports whether the mnemonic can be decoded by
+// MnemonicToEntropy.
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// IsWordCountValid reports whether a mnemonic with the given number of words
+// could be decoded by MnemonicToEntropy.
+func IsWordCountValid(count int) bool {
+	if count == 0 {
+		return false
+	}
+	switch count % phraseWords {
+	case 0, 12, 15, 18, 21:
+		return true
+	default:
+		return false
+	}
+}
+
+// bitsPerWord is the number of bits encoded by each word of a mnemonic.
+const bitsPerWord = 11
+
+// PhraseWords returns the words of the BIP39 mnemonic of entropy, which must be
+// 16 to 32 bytes long and a multiple of 4. Unlike bip39.NewMnemonic, the words
+// are the entries of the wordlist, so the mnemonic is never held in a new string
+// that cannot be wiped.
+func PhraseWords(entropy []byte) ([]string, error) {
+	if len(entropy) < 16 || len(entropy) > phraseEntropy || len(entropy)%4 != 0 {
+		return nil, fmt.Errorf("invalid entropy length: %d", len(entropy))
+	}
+
+	// the checksum is at most 8 bits long, so its first byte is enough
+	checksum := sha256.Sum256(entropy)
+	bits := append(append(make([]byte, 0, len(entropy)+1), entropy...), checksum[0])
+	defer clear(bits)
+	defer clear(checksum[:])
+
+	list := bip39.GetWordList()
+	words := make([]string, len(entropy)*8/32*3)
+	for i := range words {
+		index := 0
+		for bit := i * bitsPerWord; bit < (i+1)*bitsPerWord; bit++ {
+			index = index<<1 | int(bits[bit/8]>>(7-bit%8)&1)
+		}
+		words[i] = list[index]
+	}
+	return words, nil
+}
+
+// PhraseEntropy is the inverse of PhraseWords. It returns the entropy of a
+// single BIP39 mnemonic given as its words, after checking its checksum.
+func PhraseEntropy(words []string) ([]byte, error) {
+	entropy, valid, err := decodePhrase(words)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		clear(entropy)
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// decodePhrase returns the entropy of a single BIP39 mnemonic given as its
+// words, and whether its checksum is valid.
+func decodePhrase(words []string) (entropy []byte, valid bool, err error) {
+	if len(words) < 12 || len(words) > phraseWords || len(words)%3 != 0 {
+		return nil, false, fmt.Errorf("invalid number of words: %d", len(words))
+	}
+
+	length := len(words) / 3 * 4
+	bits := make([]byte, length+1)
+	defer clear(bits)
+	for i, word := range words {
+		index, ok := bip39.GetWordIndex(word)
+		if !ok {
+			return nil, false, &ErrInvalidWord{Position: i + 1}
+		}
+		for j := 0; j < bitsPerWord; j++ {
+			bit := i*bitsPerWord + j
+			bits[bit/8] |= byte(index>>(bitsPerWord-1-j)&1) << (7 - bit%8)
+		}
+	}
+
+	entropy = make([]byte, length)
+	copy(entropy, bits)
+	checksum := sha256.Sum256(entropy)
+	defer clear(checksum[:])
+	checksumBits := length / 4
+	mask := byte(0xff) << (8 - checksumBits)
+	return entropy, bits[length]&mask == checksum[0]&mask, nil
+}
+
+// decodeMnemonic returns the entropy of a mnemonic like MnemonicToEntropy, and
+// whether the checksums of all of its phrases are valid.
+func decodeMnemonic(mnemonic string) (entropy []byte, valid bool, err error) {
+	words := strings.Fields(mnemonic)
+	if !IsWordCountValid(len(words)) {
+		return nil, false, fmt.Errorf("invalid number of words: %d", len(words))
+	}
+
+	entropy = make([]byte, 0, len(words)/3*4)
+	valid = true
+	for start := 0; start < len(words); start += phraseWords {
+		chunk, chunkValid, err := decodePhrase(words[start:min(start+phraseWords, len(words))])
+		if err != nil {
+			clear(entropy)
+			var invalid *ErrInvalidWord
+			if errors.As(err, &invalid) {
+				return nil, false, &ErrInvalidWord{Position: start + invalid.Position}
+			}
+			return nil, false, err
+		}
+		entropy = append(entropy, chunk...)
+		clear(chunk)
+		valid = valid && chunkValid
+	}
+	return entropy, valid, nil
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestEncodableLength(t *testing.T) {
+	testCases := []struct {
+		n, expected int
+	}{
+		{n: 1, expected: 16},
+		{n: 16, expected: 16},
+		{n: 17, expected: 20},
+		{n: 32, expected: 32},
+		{n: 33, expected: 48},
+		{n: 49, expected: 52},
+		{n: 78, expected: 80},
+		{n: 97, expected: 112},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, EncodableLength(tc.n), "length %d", tc.n)
+	}
+}
+
+func TestMultiPhraseMnemonic(t *testing.T) {
+	testCases := []struct {
+		length, words int
+	}{
+		{length: 16, words: 12},
+		{length: 32, words: 24},
+		{length: 48, words: 36},
+		{length: 64, words: 48},
+		{length: 80, words: 60},
+	}
+
+	for _, tc := range testCases {
+		data := make([]byte, tc.length)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		mnemonic, err := EntropyToMnemonic(data)
+		require.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), tc.words)
+		assert.True(t, IsMnemonicValid(mnemonic))
+
+		decoded, err := MnemonicToEntropy(mnemonic)
+		require.NoError(t, err)
+		assert.Equal(t, data, decoded)
+	}
+
+	t.Run("unencodable_length", func(t *testing.T) {
+		_, err := EntropyToMnemonic(make([]byte, 36))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode 36 bytes")
+	})
+
+	t.Run("invalid_word_count", func(t *testing.T) {
+		mnemonic, err := EntropyToMnemonic(make([]byte, 48))
+		require.NoError(t, err)
+		words := strings.Fields(mnemonic)
+
+		_, err = MnemonicToEntropy(strings.Join(words[:30], " "))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid number of words: 30")
+	})
+}
+
+func TestPhraseWords(t *testing.T) {
+	for _, length := range []int{16, 20, 24, 28, 32} {
+		entropy := make([]byte, length)
+		_, err := rand.Read(entropy)
+		require.NoError(t, err)
+
+		words, err := PhraseWords(entropy)
+		require.NoError(t, err)
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		require.NoError(t, err)
+		assert.Equal(t, mnemonic, strings.Join(words, " "), "length %d", length)
+
+		decoded, err := PhraseEntropy(words)
+		require.NoError(t, err)
+		assert.Equal(t, entropy, decoded, "length %d", length)
+	}
+
+	t.Run("invalid_checksum", func(t *testing.T) {
+		words := strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+		_, err := PhraseEntropy(words)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid mnemonic checksum")
+
+		words[11] = "about"
+		entropy, err := PhraseEntropy(words)
+		require.NoError(t, err)
+		assert.Equal(t, make([]byte, 16), entropy)
+	})
+
+	t.Run("invalid_length", func(t *testing.T) {
+		_, err := PhraseWords(make([]byte, 17))
+		require.Error(t, err)
+		_, err = PhraseEntropy([]string{"abandon", "about"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+// Package oplog records what the tool did in an append-only operation log,
+// one JSON object per line. Entries never contain secrets, only facts like
+// the command run, the result of the air-gap check or the ID of a share set.
+package oplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Entry is a line of the operation log.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Event is what happened, like "airgap_check" or "split"
+	Event string `json:"event"`
+	// Details holds the non-secret facts about the event
+	Details any `json:"details,omitempty"`
+}
+
+// Log appends entries to a log file. A Log with an empty path discards them,
+// so it can always be used.
+type Log struct {
+	path    string
+	command string
+}
+
+// New returns a log that appends entries for command to the file at path,
+// which is created with 0600 permissions if it does not exist.
+func New(path, command string) *Log {
+	return &Log{path: path, command: command}
+}
+
+// Record appends an entry for event to the log.
+func (l *Log) Record(event string, details any) error {
+	if l == nil || l.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(Entry{
+		Time:    time.Now().UTC(),
+		Command: l.command,
+		Event:   event,
+		Details: details,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open operation log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write operation log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,41 @@
+package oplog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operations.log")
+	log := New(path, "split")
+	require.NoError(t, log.Record("airgap_check", map[string]string{"result": "offline"}))
+	require.NoError(t, log.Record("split", nil))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "split", entry.Command)
+	assert.Equal(t, "airgap_check", entry.Event)
+	assert.Equal(t, map[string]any{"result": "offline"}, entry.Details)
+	assert.NotContains(t, lines[1], "details")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	t.Run("disabled", func(t *testing.T) {
+		require.NoError(t, New("", "split").Record("split", nil))
+		var nilLog *Log
+		require.NoError(t, nilLog.Record("split", nil))
+	})
+}
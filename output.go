@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victorges/recovery-shards/entropy"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shards"
+)
+
+// outputFormat is the format of the output of a command, chosen with -output.
+type outputFormat string
+
+const (
+	textOutput outputFormat = "text"
+	jsonOutput outputFormat = "json"
+)
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case textOutput, jsonOutput:
+		return format, nil
+	case "":
+		return textOutput, nil
+	}
+	return "", fmt.Errorf("unknown output format: %s (expected text or json)", value)
+}
+
+// jsonDocument is the JSON output of a command. Error is set if it failed, and
+// Result is set if it got to a result, which is also the case of inspected
+// shares with a wrong checksum and of a doctor report with a no-go.
+type jsonDocument struct {
+	SchemaVersion int        `json:"schema_version"`
+	Command       string     `json:"command"`
+	Error         *jsonError `json:"error,omitempty"`
+	Result        any        `json:"result,omitempty"`
+}
+
+// jsonError is a failed command in the JSON output.
+type jsonError struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// commandOutput is the output of a command. With -output json, the result of
+// the command is written to stdout as a JSON document once it is done.
+type commandOutput struct {
+	command string
+	format  outputFormat
+	result  any
+	stdout  io.Writer
+	// messages is where the human-readable messages of the command are
+	// printed: stdout, or stderr with json, so that stdout only holds the JSON
+	// document
+	messages io.Writer
+}
+
+// newCommandOutput parses the -output flag of the command, whose output is
+// written to stdout and stderr.
+func newCommandOutput(command, value string, stdout, stderr io.Writer) (*commandOutput, error) {
+	format, err := parseOutputFormat(value)
+	if err != nil {
+		return nil, err
+	}
+	output := &commandOutput{command: command, format: format, stdout: stdout, messages: stdout}
+	if format == jsonOutput {
+		output.messages = stderr
+	}
+	return output, nil
+}
+
+// json returns whether the output is a JSON document.
+func (o *commandOutput) json() bool {
+	return o.format == jsonOutput
+}
+
+// finish writes the JSON document of the command, with its result or with err
+// if it failed, and returns err. Nothing is written for the text output.
+func (o *commandOutput) finish(err error) error {
+	if !o.json() {
+		return err
+	}
+	doc := jsonDocument{SchemaVersion: shards.SchemaVersion, Command: o.command, Result: o.result}
+	if err != nil {
+		// the prefix of the messages printed by the CLI is left out
+		message := strings.TrimPrefix(err.Error(), "error: ")
+		doc.Error = &jsonError{Message: message, ExitCode: exitCode(err)}
+	}
+	encoder := json.NewEncoder(o.stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(doc); encodeErr != nil && err == nil {
+		return fmt.Errorf("error: failed to write output: %v", encodeErr)
+	}
+	return err
+}
+
+// splitResult is the JSON result of split and generate -split.
+type splitResult struct {
+	SecretType  seed.Type `json:"secret_type"`
+	Total       int       `json:"total"`
+	Threshold   int       `json:"threshold"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	// SetID and Manifest are the ID of the share set and the path of its
+	// manifest, if the shares were saved
+	SetID    string `json:"set_id,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+	// Files lists the files the shares were saved to: one for each share, in
+	// the same order as Shares, or a single one holding all of them
+	Files  []string              `json:"files,omitempty"`
+	Shares []model.MnemonicShare `json:"shares"`
+	// Wallet holds the public wallet information shown by generate -split
+	Wallet *walletInfo `json:"wallet,omitempty"`
+}
+
+// generateResult is the JSON result of generate without -split, which either
+// outputs a mnemonic or the possible last words of a phrase.
+type generateResult struct {
+	Mnemonic   string             `json:"mnemonic,omitempty"`
+	Audit      []entropy.WordBits `json:"audit,omitempty"`
+	FinalWords []string           `json:"final_words,omitempty"`
+}
+
+// recoverResult is the JSON result of recover.
+type recoverResult struct {
+	SecretType seed.Type `json:"secret_type"`
+	// Secret is the recovered secret, unless it was written to a file, a file
+	// descriptor or the screen with -wipe
+	Secret     string `json:"secret,omitempty"`
+	OutputFile string `json:"output_file,omitempty"`
+	OutputFD   *int   `json:"output_fd,omitempty"`
+	// Shares lists the hex identifiers of the shares used
+	Shares []string `json:"shares"`
+	// Sources lists the files each share was found in, by hex identifier
+	Sources map[string][]string `json:"sources,omitempty"`
+	// Verified is whether the secret was verified against the manifest of the
+	// share set SetID
+	Verified bool   `json:"verified"`
+	SetID    string `json:"set_id,omitempty"`
+	// Custodians and MissingCustodians name the holders of the shares used
+	// and, if the manifest is known, of the other shares of the set
+	Custodians        []string `json:"custodians"`
+	MissingCustodians []string `json:"missing_custodians,omitempty"`
+	secretDetails
+	Wallet *walletInfo `json:"wallet,omitempty"`
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package secure
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockMemory locks the current and future memory of the process. It is only
+// attempted when the memory lock limit allows it, since exceeding the limit
+// with MCL_FUTURE makes later allocations fail.
+func lockMemory() error {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &limit); err != nil {
+		return err
+	}
+	if limit.Cur != unix.RLIM_INFINITY && os.Geteuid() != 0 {
+		return fmt.Errorf("memory lock limit is %d bytes (raise it with ulimit -l unlimited)", limit.Cur)
+	}
+
+	// MCL_ONFAULT only locks pages once they are used, instead of the whole
+	// address space reserved by the Go runtime
+	err := unix.Mlockall(unix.MCL_CURRENT | unix.MCL_FUTURE | unix.MCL_ONFAULT)
+	if err == unix.EINVAL {
+		err = unix.Mlockall(unix.MCL_CURRENT | unix.MCL_FUTURE)
+	}
+	return err
+}
+
+func disableDumpable() error {
+	return unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+}
+
+func disableCoreDumps() error {
+	return unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{Cur: 0, Max: 0})
+}
+
+// swapActive reports whether any swap area is listed in /proc/swaps, after its
+// header line.
+func swapActive() (bool, error) {
+	file, err := os.Open("/proc/swaps")
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	areas := 0
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			areas++
+		}
+	}
+	return areas > 1, scanner.Err()
+}
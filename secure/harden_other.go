@@ -0,0 +1,19 @@
+//go:build !linux
+
+package secure
+
+func lockMemory() error {
+	return errUnsupported
+}
+
+func disableDumpable() error {
+	return errUnsupported
+}
+
+func disableCoreDumps() error {
+	return errUnsupported
+}
+
+func swapActive() (bool, error) {
+	return false, errUnsupported
+}
@@ -0,0 +1,71 @@
+// Package secure limits how long secrets stay in memory and where they can
+// leak to: it wipes buffers once they are used, and hardens the process
+// against swapping and core dumps.
+package secure
+
+import (
+	"errors"
+	"runtime"
+)
+
+// errUnsupported is returned by the hardening steps that are not available on
+// the current platform.
+var errUnsupported = errors.New("not supported on " + runtime.GOOS)
+
+// Wipe zeroes the buffers.
+func Wipe(bufs ...[]byte) {
+	for _, buf := range bufs {
+		clear(buf)
+	}
+	// keep the buffers alive until they are cleared, so the writes are not
+	// considered dead
+	runtime.KeepAlive(bufs)
+}
+
+// Options selects the hardening steps applied by Harden.
+type Options struct {
+	// LockMemory locks all the memory of the process with mlockall, so that
+	// secrets are never written to swap.
+	LockMemory bool
+	// NoDump marks the process as not dumpable, which also stops other
+	// processes of the same user from reading its memory with ptrace.
+	NoDump bool
+	// NoCore disables core dumps by setting their size limit to 0.
+	NoCore bool
+	// SwapWarning warns if swap is active, which can hold secrets if the
+	// memory could not be locked.
+	SwapWarning bool
+}
+
+// DefaultOptions returns options with every hardening step enabled.
+func DefaultOptions() Options {
+	return Options{LockMemory: true, NoDump: true, NoCore: true, SwapWarning: true}
+}
+
+// Harden applies the selected hardening steps to the current process. Steps
+// that fail or are not supported do not stop the others, and are reported as
+// warnings instead.
+func Harden(opts Options) []string {
+	var warnings []string
+	if opts.LockMemory {
+		if err := lockMemory(); err != nil {
+			warnings = append(warnings, "could not lock memory, secrets may be swapped to disk: "+err.Error())
+		}
+	}
+	if opts.NoDump {
+		if err := disableDumpable(); err != nil {
+			warnings = append(warnings, "could not mark the process as not dumpable: "+err.Error())
+		}
+	}
+	if opts.NoCore {
+		if err := disableCoreDumps(); err != nil {
+			warnings = append(warnings, "could not disable core dumps: "+err.Error())
+		}
+	}
+	if opts.SwapWarning {
+		if active, err := swapActive(); err == nil && active {
+			warnings = append(warnings, "swap is active, secrets in memory could be written to disk")
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,18 @@
+package secure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWipe(t *testing.T) {
+	a, b := []byte("secret"), []byte{1, 2, 3}
+	Wipe(a, b, nil)
+	assert.Equal(t, make([]byte, 6), a)
+	assert.Equal(t, make([]byte, 3), b)
+}
+
+func TestHardenDisabled(t *testing.T) {
+	assert.Empty(t, Harden(Options{}))
+}
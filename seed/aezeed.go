@@ -0,0 +1,86 @@
+package seed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// aezeedWords is the number of words in an LND aezeed mnemonic.
+	aezeedWords = 24
+	// aezeedLength is the length of the enciphered seed encoded by the words:
+	// version (1 byte), ciphertext (23 bytes), salt (5 bytes), checksum (4 bytes).
+	aezeedLength = 33
+	// aezeedVersion is the only external cipher seed version defined by LND.
+	aezeedVersion = 0
+	// aezeedChecksumOffset is the offset of the checksum in the cipher seed.
+	aezeedChecksumOffset = aezeedLength - 4
+)
+
+var aezeedCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// aezeedCodec handles LND aezeed cipher seeds. Their 24 words from the BIP39
+// wordlist are a plain 264-bit encoding of the enciphered seed, which is the
+// raw bytes of the secret. The seed is never deciphered, so no passphrase is
+// needed to split or recover it.
+type aezeedCodec struct{}
+
+func (aezeedCodec) Decode(text string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) != aezeedWords {
+		return nil, fmt.Errorf("aezeed must contain exactly %d words, got %d", aezeedWords, len(words))
+	}
+
+	num := new(big.Int)
+	for _, word := range words {
+		index, ok := bip39.GetWordIndex(word)
+		if !ok {
+			return nil, fmt.Errorf("invalid word in aezeed: %s", word)
+		}
+		num.Lsh(num, 11)
+		num.Or(num, big.NewInt(int64(index)))
+	}
+
+	data := num.FillBytes(make([]byte, aezeedLength))
+	if err := validateAezeed(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (aezeedCodec) Encode(data []byte) (string, error) {
+	if len(data) != aezeedLength {
+		return "", fmt.Errorf("invalid aezeed length: %d", len(data))
+	}
+	if err := validateAezeed(data); err != nil {
+		return "", err
+	}
+
+	wordList := bip39.GetWordList()
+	num := new(big.Int).SetBytes(data)
+	mask := big.NewInt(0x7ff)
+	words := make([]string, aezeedWords)
+	for i := aezeedWords - 1; i >= 0; i-- {
+		words[i] = wordList[new(big.Int).And(num, mask).Int64()]
+		num.Rsh(num, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// validateAezeed checks the version and the CRC32C checksum of an enciphered
+// aezeed.
+func validateAezeed(data []byte) error {
+	if data[0] != aezeedVersion {
+		return fmt.Errorf("unsupported aezeed version: %d", data[0])
+	}
+	expected := crc32.Checksum(data[:aezeedChecksumOffset], aezeedCRCTable)
+	if got := binary.BigEndian.Uint32(data[aezeedChecksumOffset:]); got != expected {
+		return fmt.Errorf("invalid aezeed checksum (expected: %08x, got: %08x)", expected, got)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package seed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// bip39Codec handles BIP39 mnemonics, whose raw bytes are the entropy.
+type bip39Codec struct{}
+
+func (bip39Codec) Decode(text string) ([]byte, error) {
+	mnemonic := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic phrase: %w", err)
+	}
+	return entropy, nil
+}
+
+func (bip39Codec) Encode(data []byte) (string, error) {
+	return bip39.NewMnemonic(data)
+}
@@ -0,0 +1,110 @@
+package seed
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// electrumSeedVersions maps the hex prefixes of the HMAC-SHA512 of a new-style
+// Electrum seed to the seed version they identify.
+var electrumSeedVersions = []struct {
+	prefix, version string
+}{
+	{prefix: "01", version: "standard"},
+	{prefix: "100", version: "segwit"},
+	{prefix: "101", version: "2fa"},
+	{prefix: "102", version: "2fa_segwit"},
+}
+
+var electrumRadix = big.NewInt(2048)
+
+// ElectrumSeedVersion returns the version of a new-style Electrum seed phrase
+// (standard, segwit, 2fa or 2fa_segwit), or an empty string if the phrase is
+// not a valid Electrum seed.
+func ElectrumSeedVersion(phrase string) string {
+	mac := hmac.New(sha512.New, []byte("Seed version"))
+	mac.Write([]byte(normalizeElectrumPhrase(phrase)))
+	digest := hex.EncodeToString(mac.Sum(nil))
+
+	for _, v := range electrumSeedVersions {
+		if strings.HasPrefix(digest, v.prefix) {
+			return v.version
+		}
+	}
+	return ""
+}
+
+// normalizeElectrumPhrase lowercases the phrase and collapses whitespace,
+// which is what Electrum normalization amounts to for the English wordlist.
+func normalizeElectrumPhrase(phrase string) string {
+	return strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+}
+
+// electrumCodec handles new-style Electrum seeds. These use the BIP39 English
+// wordlist but encode a number in base 2048, least significant word first,
+// and carry a version prefix instead of a checksum. The raw bytes are the word
+// count followed by that number in big-endian, so the exact phrase can be
+// restored even if it ends with words of index zero.
+type electrumCodec struct{}
+
+func (electrumCodec) Decode(text string) ([]byte, error) {
+	words := strings.Fields(normalizeElectrumPhrase(text))
+	if len(words) == 0 || len(words) > 0xff {
+		return nil, fmt.Errorf("invalid number of words in Electrum seed: %d", len(words))
+	}
+
+	num := new(big.Int)
+	for i := len(words) - 1; i >= 0; i-- {
+		index, ok := bip39.GetWordIndex(words[i])
+		if !ok {
+			return nil, fmt.Errorf("invalid word in Electrum seed: %s", words[i])
+		}
+		num.Mul(num, electrumRadix)
+		num.Add(num, big.NewInt(int64(index)))
+	}
+
+	if ElectrumSeedVersion(text) == "" {
+		return nil, fmt.Errorf("invalid Electrum seed version")
+	}
+
+	data := make([]byte, 1+electrumNumberLength(len(words)))
+	data[0] = byte(len(words))
+	num.FillBytes(data[1:])
+	return data, nil
+}
+
+func (electrumCodec) Encode(data []byte) (string, error) {
+	if len(data) < 1 || len(data) != 1+electrumNumberLength(int(data[0])) {
+		return "", fmt.Errorf("invalid Electrum seed data length: %d", len(data))
+	}
+
+	wordList := bip39.GetWordList()
+	num := new(big.Int).SetBytes(data[1:])
+	digit := new(big.Int)
+	words := make([]string, data[0])
+	for i := range words {
+		num.DivMod(num, electrumRadix, digit)
+		words[i] = wordList[digit.Int64()]
+	}
+	if num.Sign() != 0 {
+		return "", fmt.Errorf("Electrum seed number too large for %d words", len(words))
+	}
+
+	phrase := strings.Join(words, " ")
+	if ElectrumSeedVersion(phrase) == "" {
+		return "", fmt.Errorf("invalid Electrum seed version")
+	}
+	return phrase, nil
+}
+
+// electrumNumberLength returns the number of bytes needed to hold the number
+// encoded by an Electrum seed with the given word count.
+func electrumNumberLength(words int) int {
+	return (words*11 + 7) / 8
+}
@@ -0,0 +1,81 @@
+package seed
+
+import (
+	"fmt"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/wallet"
+)
+
+// xprvCodec handles BIP32 extended private keys. The raw bytes are the full
+// 78-byte serialization, so the exact string can be restored including its
+// depth, parent fingerprint and child number.
+type xprvCodec struct{}
+
+func (xprvCodec) Decode(text string) ([]byte, error) {
+	key, err := wallet.ParseExtendedKey(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended private key: %w", err)
+	}
+	if !key.IsPrivate() {
+		return nil, fmt.Errorf("extended key is not private")
+	}
+	return key.Serialize(), nil
+}
+
+func (xprvCodec) Encode(data []byte) (string, error) {
+	key, err := wallet.DecodeExtendedKey(data)
+	if err != nil {
+		return "", err
+	}
+	if !key.IsPrivate() {
+		return "", fmt.Errorf("extended key is not private")
+	}
+	return key.String(), nil
+}
+
+// wifCodec handles WIF private keys. The raw bytes are the version byte, the
+// private key and the compression flag.
+type wifCodec struct{}
+
+func (wifCodec) Decode(text string) ([]byte, error) {
+	wif, err := wallet.ParseWIF(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WIF private key: %w", err)
+	}
+	return wif.Serialize(), nil
+}
+
+func (wifCodec) Encode(data []byte) (string, error) {
+	wif, err := wallet.DecodeWIF(data)
+	if err != nil {
+		return "", err
+	}
+	return wif.String(), nil
+}
+
+// MasterKey returns the BIP32 master extended private key of the secret. The
+// master key of a BIP39 mnemonic is derived on mainnet with an empty
+// passphrase, while an extended private key must itself be a master key.
+// Other secret types do not have a BIP32 master key.
+func (s Secret) MasterKey() (*wallet.ExtendedKey, error) {
+	switch s.Type {
+	case BIP39:
+		mnemonic, err := s.Text()
+		if err != nil {
+			return nil, err
+		}
+		return wallet.NewMasterKey(bip39.NewSeed(mnemonic, ""), wallet.MainNet)
+	case XPRV:
+		key, err := wallet.DecodeExtendedKey(s.Data)
+		if err != nil {
+			return nil, err
+		}
+		if key.Depth != 0 {
+			return nil, fmt.Errorf("extended key is not a master key (depth %d)", key.Depth)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%s does not have a BIP32 master key", s.Type.Description())
+	}
+}
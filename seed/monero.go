@@ -0,0 +1,94 @@
+package seed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+const (
+	// moneroSeedWords is the number of words in a Monero seed, including the
+	// checksum word.
+	moneroSeedWords = 25
+	// moneroPrefixLength is the number of letters of each word used to compute
+	// the checksum word.
+	moneroPrefixLength = 3
+	// moneroKeyLength is the length of the private spend key encoded by a seed.
+	moneroKeyLength = 32
+)
+
+var (
+	moneroWords   = strings.Fields(moneroWordList)
+	moneroIndexes = func() map[string]uint32 {
+		indexes := make(map[string]uint32, len(moneroWords))
+		for i, word := range moneroWords {
+			indexes[word] = uint32(i)
+		}
+		return indexes
+	}()
+)
+
+// moneroCodec handles Monero 25-word seeds. Every 3 words encode 4 bytes of the
+// private spend key, which is the raw bytes of the secret, and the last word
+// is a checksum chosen among the previous 24.
+type moneroCodec struct{}
+
+func (moneroCodec) Decode(text string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) != moneroSeedWords {
+		return nil, fmt.Errorf("Monero seed must contain exactly %d words, got %d", moneroSeedWords, len(words))
+	}
+	if expected := moneroChecksumWord(words[:moneroSeedWords-1]); words[moneroSeedWords-1] != expected {
+		return nil, fmt.Errorf("invalid Monero seed checksum word (expected: %s, got: %s)", expected, words[moneroSeedWords-1])
+	}
+
+	n := uint64(len(moneroWords))
+	key := make([]byte, moneroKeyLength)
+	for i := 0; i < moneroKeyLength/4; i++ {
+		var w [3]uint64
+		for j := range w {
+			word := words[i*3+j]
+			index, ok := moneroIndexes[word]
+			if !ok {
+				return nil, fmt.Errorf("invalid word in Monero seed: %s", word)
+			}
+			w[j] = uint64(index)
+		}
+
+		val := w[0] + n*((n-w[0]+w[1])%n) + n*n*((n-w[1]+w[2])%n)
+		if val > 0xffffffff {
+			return nil, fmt.Errorf("invalid Monero seed words at position %d", i*3+1)
+		}
+		binary.LittleEndian.PutUint32(key[i*4:], uint32(val))
+	}
+	return key, nil
+}
+
+func (moneroCodec) Encode(data []byte) (string, error) {
+	if len(data) != moneroKeyLength {
+		return "", fmt.Errorf("invalid Monero key length: %d", len(data))
+	}
+
+	n := uint32(len(moneroWords))
+	words := make([]string, 0, moneroSeedWords)
+	for i := 0; i < moneroKeyLength/4; i++ {
+		val := binary.LittleEndian.Uint32(data[i*4:])
+		w1 := val % n
+		w2 := (val/n + w1) % n
+		w3 := (val/n/n + w2) % n
+		words = append(words, moneroWords[w1], moneroWords[w2], moneroWords[w3])
+	}
+	words = append(words, moneroChecksumWord(words))
+	return strings.Join(words, " "), nil
+}
+
+// moneroChecksumWord returns the checksum word for the first 24 words of a
+// Monero seed: the word at the index given by the CRC32 of their prefixes.
+func moneroChecksumWord(words []string) string {
+	var prefixes strings.Builder
+	for _, word := range words {
+		prefixes.WriteString(word[:min(moneroPrefixLength, len(word))])
+	}
+	return words[crc32.ChecksumIEEE([]byte(prefixes.String()))%uint32(len(words))]
+}
@@ -0,0 +1,232 @@
+// Package seed converts the different kinds of wallet secrets supported by
+// the tool to and from the raw bytes that are split into shares.
+//
+// BIP39 mnemonics are split on their entropy directly, so their shares are
+// identical to the ones created by earlier versions of the tool. All other
+// secret types are wrapped in an envelope before being split:
+//
+//	tag (1 byte) | length (1 byte) | data | zero padding
+//
+// The envelope is padded to a length longer than any BIP39 entropy (32 bytes)
+// that can be encoded as share mnemonics, so a recovered payload is always
+// unambiguously either raw BIP39 entropy or an envelope.
+package seed
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+)
+
+// Type identifies the format of a secret.
+type Type string
+
+const (
+	// Auto detects the type of the secret from its text form.
+	Auto Type = "auto"
+	// BIP39 is a BIP39 mnemonic phrase.
+	BIP39 Type = "bip39"
+	// XPRV is a BIP32 extended private key (xprv or tprv).
+	XPRV Type = "xprv"
+	// WIF is a private key in Wallet Import Format.
+	WIF Type = "wif"
+	// Electrum is a new-style Electrum seed phrase.
+	Electrum Type = "electrum"
+	// Monero is a Monero 25-word seed phrase.
+	Monero Type = "monero"
+	// Aezeed is an LND aezeed cipher seed.
+	Aezeed Type = "aezeed"
+)
+
+// maxBIP39Entropy is the length of the entropy of a 24-word BIP39 mnemonic.
+const maxBIP39Entropy = 32
+
+// Codec converts between the text form of a secret type and its raw bytes.
+type Codec interface {
+	// Decode validates the text form of a secret and returns its raw bytes.
+	Decode(text string) ([]byte, error)
+	// Encode returns the text form of the raw secret bytes.
+	Encode(data []byte) (string, error)
+}
+
+type codecEntry struct {
+	// tag identifies the type in the payload envelope. Unused for BIP39.
+	tag         byte
+	description string
+	codec       Codec
+}
+
+var codecs = map[Type]codecEntry{
+	BIP39:    {description: "mnemonic phrase", codec: bip39Codec{}},
+	XPRV:     {tag: 0x01, description: "extended private key", codec: xprvCodec{}},
+	WIF:      {tag: 0x02, description: "WIF private key", codec: wifCodec{}},
+	Electrum: {tag: 0x03, description: "Electrum seed phrase", codec: electrumCodec{}},
+	Monero:   {tag: 0x04, description: "Monero seed phrase", codec: moneroCodec{}},
+	Aezeed:   {tag: 0x05, description: "aezeed cipher seed", codec: aezeedCodec{}},
+}
+
+// Description returns a human-readable description of the secret type.
+func (t Type) Description() string {
+	if entry, ok := codecs[t]; ok {
+		return entry.description
+	}
+	return string(t)
+}
+
+// ParseType parses the name of a secret type.
+func ParseType(name string) (Type, error) {
+	t := Type(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := codecs[t]; !ok && t != Auto {
+		return "", fmt.Errorf("unknown seed type: %s", name)
+	}
+	return t, nil
+}
+
+// Secret is a secret of a given type in its raw binary form.
+type Secret struct {
+	Type Type
+	// Data holds the raw bytes of the secret, as returned by its codec
+	Data []byte
+}
+
+// Parse validates the text form of a secret of the given type. If the type is
+// Auto, it is detected from the text with Detect.
+func Parse(t Type, text string) (Secret, error) {
+	if t == Auto {
+		t = Detect(text)
+	}
+	entry, ok := codecs[t]
+	if !ok {
+		return Secret{}, fmt.Errorf("unknown seed type: %s", t)
+	}
+
+	data, err := entry.codec.Decode(strings.TrimSpace(text))
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{Type: t, Data: data}, nil
+}
+
+// Detect guesses the type of the secret in text. A single token is parsed as
+// an extended key if it starts with a known prefix or as a WIF otherwise,
+// while anything else is considered a BIP39 mnemonic. Electrum and aezeed seeds
+// are never detected, since their phrases use the BIP39 wordlist as well, and
+// neither are Monero seeds.
+func Detect(text string) Type {
+	fields := strings.Fields(text)
+	if len(fields) != 1 {
+		return BIP39
+	}
+	if strings.HasPrefix(fields[0], "xprv") || strings.HasPrefix(fields[0], "tprv") {
+		return XPRV
+	}
+	return WIF
+}
+
+// Text returns the text form of the secret.
+func (s Secret) Text() (string, error) {
+	entry, ok := codecs[s.Type]
+	if !ok {
+		return "", fmt.Errorf("unknown seed type: %s", s.Type)
+	}
+	return entry.codec.Encode(s.Data)
+}
+
+// WriteText writes the text form of the secret to w. The words of a BIP39
+// mnemonic are written one at a time, straight from the wordlist, so the
+// mnemonic is never held in a string that cannot be wiped. Other types are
+// written from their Text.
+func (s Secret) WriteText(w io.Writer) error {
+	if s.Type != BIP39 {
+		text, err := s.Text()
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, text)
+		return err
+	}
+
+	words, err := model.PhraseWords(s.Data)
+	if err != nil {
+		return err
+	}
+	for i, word := range words {
+		if i > 0 {
+			if _, err := io.WriteString(w, " "); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wipe zeroes the data of the secret.
+func (s Secret) Wipe() {
+	secure.Wipe(s.Data)
+}
+
+// Payload returns the bytes to be split into shares for the secret, which is
+// the raw entropy for BIP39 mnemonics and an envelope for any other type.
+func (s Secret) Payload() ([]byte, error) {
+	entry, ok := codecs[s.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown seed type: %s", s.Type)
+	}
+	if s.Type == BIP39 {
+		return bytes.Clone(s.Data), nil
+	}
+	if len(s.Data) > 0xff {
+		return nil, fmt.Errorf("secret too long: %d bytes", len(s.Data))
+	}
+
+	length := model.EncodableLength(max(maxBIP39Entropy+1, len(s.Data)+2))
+	payload := make([]byte, length)
+	payload[0] = entry.tag
+	payload[1] = byte(len(s.Data))
+	copy(payload[2:], s.Data)
+	return payload, nil
+}
+
+// FromPayload is the inverse of Payload. The secret data is validated with the
+// codec of its type.
+func FromPayload(payload []byte) (Secret, error) {
+	if len(payload) <= maxBIP39Entropy {
+		if _, err := model.PhraseWords(payload); err != nil {
+			return Secret{}, fmt.Errorf("invalid mnemonic entropy: %w", err)
+		}
+		return Secret{Type: BIP39, Data: bytes.Clone(payload)}, nil
+	}
+
+	secret := Secret{}
+	for t, entry := range codecs {
+		if t != BIP39 && entry.tag == payload[0] {
+			secret.Type = t
+		}
+	}
+	if secret.Type == "" {
+		return Secret{}, fmt.Errorf("unknown secret tag: %02x", payload[0])
+	}
+
+	length := int(payload[1])
+	if 2+length > len(payload) {
+		return Secret{}, fmt.Errorf("invalid secret length: %d", length)
+	}
+	for _, b := range payload[2+length:] {
+		if b != 0 {
+			return Secret{}, fmt.Errorf("invalid secret padding")
+		}
+	}
+
+	secret.Data = bytes.Clone(payload[2 : 2+length])
+	if _, err := secret.Text(); err != nil {
+		return Secret{}, fmt.Errorf("invalid %s: %w", secret.Type.Description(), err)
+	}
+	return secret, nil
+}
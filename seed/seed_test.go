@@ -0,0 +1,313 @@
+package seed
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMnemonic = "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"
+	testXprv     = "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"
+	testWIF      = "Kx45GeUBSMPReYQwgXiKhG9FzNXrnCeutJp4yjTd5kKxCitadm3C"
+)
+
+func TestSecretPayloadRoundtrip(t *testing.T) {
+	testCases := []struct {
+		name          string
+		text          string
+		expectedType  Type
+		payloadLength int
+	}{
+		{
+			name:          "bip39",
+			text:          testMnemonic,
+			expectedType:  BIP39,
+			payloadLength: 32,
+		},
+		{
+			name:          "xprv",
+			text:          testXprv,
+			expectedType:  XPRV,
+			payloadLength: 80,
+		},
+		{
+			name:          "wif",
+			text:          testWIF,
+			expectedType:  WIF,
+			payloadLength: 48,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := Parse(Auto, tc.text)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedType, secret.Type)
+
+			payload, err := secret.Payload()
+			require.NoError(t, err)
+			assert.Len(t, payload, tc.payloadLength)
+
+			recovered, err := FromPayload(payload)
+			require.NoError(t, err)
+			assert.Equal(t, secret, recovered)
+
+			text, err := recovered.Text()
+			require.NoError(t, err)
+			assert.Equal(t, tc.text, text)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		seedType Type
+		text     string
+		errMsg   string
+	}{
+		{
+			name:     "invalid_mnemonic",
+			seedType: Auto,
+			text:     "goose apple ecology",
+			errMsg:   "invalid mnemonic phrase",
+		},
+		{
+			name:     "xprv_bad_checksum",
+			seedType: Auto,
+			text:     testXprv[:len(testXprv)-1] + "8",
+			errMsg:   "invalid base58check checksum",
+		},
+		{
+			name:     "xpub_is_not_private",
+			seedType: XPRV,
+			text:     "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+			errMsg:   "extended key is not private",
+		},
+		{
+			name:     "wif_bad_checksum",
+			seedType: Auto,
+			text:     "Kx45GeUBSMPReYQwgXiKhG9FzNXrnCeutJp4yjTd5kKxCitadm3D",
+			errMsg:   "invalid base58check checksum",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.seedType, tc.text)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestFromPayloadErrors(t *testing.T) {
+	secret, err := Parse(WIF, testWIF)
+	require.NoError(t, err)
+	payload, err := secret.Payload()
+	require.NoError(t, err)
+
+	t.Run("unknown_tag", func(t *testing.T) {
+		corrupted := append([]byte{0x7f}, payload[1:]...)
+		_, err := FromPayload(corrupted)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown secret tag")
+	})
+
+	t.Run("invalid_padding", func(t *testing.T) {
+		corrupted := append([]byte{}, payload...)
+		corrupted[len(corrupted)-1] = 0x01
+		_, err := FromPayload(corrupted)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid secret padding")
+	})
+}
+
+func TestElectrumSeed(t *testing.T) {
+	// Vectors from Electrum's test suite
+	testCases := []struct {
+		name    string
+		phrase  string
+		version string
+	}{
+		{
+			name:    "standard",
+			phrase:  "blast uniform dragon fiscal ensure vast young utility dinosaur abandon rookie sure",
+			version: "standard",
+		},
+		{
+			name:    "segwit",
+			phrase:  "wild father tree among universe such mobile favorite target dynamic credit identify",
+			version: "segwit",
+		},
+		{
+			name:    "2fa",
+			phrase:  "science dawn member doll dutch real can brick knife deny drive list",
+			version: "2fa",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.version, ElectrumSeedVersion(tc.phrase))
+
+			secret, err := Parse(Electrum, "  "+strings.ToUpper(tc.phrase)+"\n")
+			require.NoError(t, err)
+			assert.Len(t, secret.Data, 18)
+
+			payload, err := secret.Payload()
+			require.NoError(t, err)
+			recovered, err := FromPayload(payload)
+			require.NoError(t, err)
+
+			phrase, err := recovered.Text()
+			require.NoError(t, err)
+			assert.Equal(t, tc.phrase, phrase)
+		})
+	}
+
+	t.Run("bip39_phrase_is_not_electrum", func(t *testing.T) {
+		_, err := Parse(Electrum, testMnemonic)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid Electrum seed version")
+	})
+
+	t.Run("invalid_word", func(t *testing.T) {
+		_, err := Parse(Electrum, "wild father tree among universe such mobile favorite target dynamic credit identity")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid word in Electrum seed: identity")
+	})
+}
+
+func TestMoneroSeed(t *testing.T) {
+	// Seeds from Monero's functional tests, with the private spend keys of the
+	// wallets they restore
+	testCases := []struct {
+		name   string
+		phrase string
+		key    string
+	}{
+		{
+			name:   "velvet",
+			phrase: "velvet lymph giddy number token physics poetry unquoted nibs useful sabotage limits benches lifestyle eden nitrogen anvil fewest avoid batch vials washing fences goat unquoted",
+			key:    "148d78d2aba7dbca5cd8f6abcfb0b3c009ffbdbea1ff373d50ed94d78286640e",
+		},
+		{
+			name:   "peeled",
+			phrase: "peeled mixture ionic radar utopia puddle buying illness nuns gadget river spout cavernous bounced paradise drunk looking cottage jump tequila melting went winter adjust spout",
+			key:    "609ae8e228a871c37b61292ff898dd144db5d784804cc4a971bf74aff3acb70a",
+		},
+		{
+			name:   "sequence",
+			phrase: "sequence atlas unveil summon pebbles tuesday beer rudely snake rockets different fuselage woven tagged bested dented vegan hover rapid fawns obvious muppet randomly seasons randomly",
+			key:    "b0ef6bd527b9b23b9ceef70dc8b4cd1ee83ca14541964e764ad23f5151204f0f",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := Parse(Monero, tc.phrase)
+			require.NoError(t, err)
+			assert.Equal(t, tc.key, hex.EncodeToString(secret.Data))
+
+			payload, err := secret.Payload()
+			require.NoError(t, err)
+			recovered, err := FromPayload(payload)
+			require.NoError(t, err)
+
+			phrase, err := recovered.Text()
+			require.NoError(t, err)
+			assert.Equal(t, tc.phrase, phrase)
+		})
+	}
+
+	t.Run("invalid_checksum_word", func(t *testing.T) {
+		words := strings.Fields(testCases[0].phrase)
+		words[24] = "velvet"
+		_, err := Parse(Monero, strings.Join(words, " "))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid Monero seed checksum word (expected: unquoted, got: velvet)")
+	})
+
+	t.Run("invalid_word", func(t *testing.T) {
+		_, err := Parse(Monero, strings.Replace(testCases[0].phrase, "velvet", "abandon", 1))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid")
+	})
+}
+
+func TestAezeed(t *testing.T) {
+	// Version 0 test vectors from LND's aezeed package
+	testCases := []struct {
+		name   string
+		phrase string
+	}{
+		{
+			name:   "default_passphrase",
+			phrase: "ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+		},
+		{
+			name:   "custom_passphrase",
+			phrase: "able tree stool crush transfer cloud cross three profit outside hen citizen plate ride require leg siren drum success suggest drink require fiscal upgrade",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := Parse(Aezeed, tc.phrase)
+			require.NoError(t, err)
+			assert.Len(t, secret.Data, 33)
+			assert.Equal(t, []byte("salt1"), secret.Data[24:29])
+
+			payload, err := secret.Payload()
+			require.NoError(t, err)
+			recovered, err := FromPayload(payload)
+			require.NoError(t, err)
+
+			phrase, err := recovered.Text()
+			require.NoError(t, err)
+			assert.Equal(t, tc.phrase, phrase)
+		})
+	}
+
+	t.Run("invalid_checksum", func(t *testing.T) {
+		phrase := strings.Replace(testCases[0].phrase, "royal", "roast", 1)
+		_, err := Parse(Aezeed, phrase)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid aezeed checksum")
+	})
+
+	t.Run("bip39_mnemonic_is_not_aezeed", func(t *testing.T) {
+		_, err := Parse(Aezeed, testMnemonic)
+		require.Error(t, err)
+	})
+}
+
+func TestMasterKey(t *testing.T) {
+	secret, err := Parse(BIP39, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	require.NoError(t, err)
+	master, err := secret.MasterKey()
+	require.NoError(t, err)
+	fp := master.Fingerprint()
+	assert.Equal(t, "73c5da0a", hex.EncodeToString(fp[:]))
+
+	t.Run("xprv_not_master", func(t *testing.T) {
+		secret, err := Parse(XPRV, testXprv)
+		require.NoError(t, err)
+		_, err = secret.MasterKey()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a master key")
+	})
+
+	t.Run("wif", func(t *testing.T) {
+		secret, err := Parse(WIF, testWIF)
+		require.NoError(t, err)
+		_, err = secret.MasterKey()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WIF private key does not have a BIP32 master key")
+	})
+}
@@ -0,0 +1,40 @@
+package shamir
+
+// Arithmetic in GF(2^8) with the AES reduction polynomial x^8+x^4+x^3+x+1.
+// None of the operations branch on or index memory by their operands, so they
+// run in constant time.
+
+// add adds (or subtracts) two elements.
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// mult multiplies two elements.
+func mult(a, b byte) byte {
+	var r byte
+	for i := 7; i >= 0; i-- {
+		r = (-(b >> i & 1) & a) ^ (-(r >> 7) & 0x1b) ^ (r + r)
+	}
+	return r
+}
+
+// inverse returns the multiplicative inverse of a non-zero element, computed
+// as a^254 with a fixed chain of multiplications. The inverse of 0 is 0.
+func inverse(a byte) byte {
+	b := mult(a, a)   // a^2
+	c := mult(a, b)   // a^3
+	b = mult(c, c)    // a^6
+	b = mult(b, b)    // a^12
+	c = mult(b, c)    // a^15
+	b = mult(b, b)    // a^24
+	b = mult(b, b)    // a^48
+	b = mult(b, c)    // a^63
+	b = mult(b, b)    // a^126
+	b = mult(a, b)    // a^127
+	return mult(b, b) // a^254
+}
+
+// div divides a by a non-zero element b.
+func div(a, b byte) byte {
+	return mult(a, inverse(b))
+}
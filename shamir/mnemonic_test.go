@@ -0,0 +1,66 @@
+package shamir_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/shamir"
+)
+
+// TestCombineVaultMnemonicShares combines shares written by command.Split
+// before this package replaced github.com/hashicorp/vault/shamir, to check
+// that the shares already handed out can still be recovered.
+func TestCombineVaultMnemonicShares(t *testing.T) {
+	testCases := []struct {
+		name     string
+		shares   [][2]string
+		mnemonic string
+	}{
+		{
+			name: "2_of_3",
+			shares: [][2]string{
+				{"0xade1", "drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"},
+				{"0xf606", "ride magnet elbow uniform slight fat unlock attitude calm blouse pretty axis health dentist shaft gorilla exist fossil hunt chaos frame panther ankle please"},
+			},
+			mnemonic: "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry",
+		},
+		{
+			name: "3_of_5",
+			shares: [][2]string{
+				{"0x5954", "ankle salad deposit junior arrest raw box place cradle brand force boat weird involve claw neck paper vast riot prize embrace rough pelican eight"},
+				{"0x9b9f", "aerobic boat baby injury animal frequent artwork happy autumn foam rebuild segment rude fringe mix calm kite patrol garbage model material federal brass hazard"},
+				{"0xf78d", "slam border talk switch suspect wear deal core undo cement impact route hollow pelican peasant give hour ski huge raccoon elite arrest theme rare"},
+			},
+			mnemonic: "border area early digital pen menu defy surround dove brand tongue dad eternal jazz position kid fatigue pelican cradle wood fortune outer loyal current",
+		},
+		{
+			name: "2_of_3_other_split",
+			shares: [][2]string{
+				{"0xd3e8", "scrap fold tenant lamp hawk deliver load trouble lake strike burger piece mass apart voice glove write process split cart bachelor twin later either"},
+				{"0xb9f7", "clutch hollow tennis odor amazing frown steak voyage high label avoid region couch exhibit drive frown pig photo two bright pluck acoustic mesh favorite"},
+			},
+			mnemonic: "excuse glare tenant iron tide march health hurdle hood venue soldier file school soon digital estate fox hazard mesh cross right effort whale whip",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := make([][]byte, len(tc.shares))
+			for i, s := range tc.shares {
+				share, err := model.NewMnemonicShare(s[0], s[1])
+				require.NoError(t, err)
+				parts[i], err = share.ToShamir()
+				require.NoError(t, err)
+			}
+
+			// the mnemonic was split as its BIP-39 entropy
+			secret, err := shamir.Combine(parts)
+			require.NoError(t, err)
+			mnemonic, err := model.EntropyToMnemonic(secret)
+			require.NoError(t, err)
+			assert.Equal(t, tc.mnemonic, mnemonic)
+		})
+	}
+}
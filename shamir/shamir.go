@@ -0,0 +1,144 @@
+// Package shamir implements Shamir's Secret Sharing over GF(2^8).
+//
+// Every byte of the secret is the intercept of its own random polynomial of
+// degree threshold-1, and a share holds the value of all the polynomials at
+// the x coordinate of the share. A share for a secret of length L is laid out
+// as L+1 bytes:
+//
+//	y_1 | y_2 | ... | y_L | x
+//
+// where y_i is the value at x of the polynomial for the i-th byte of the
+// secret. This is the same layout and field (with the AES reduction polynomial)
+// as github.com/hashicorp/vault/shamir, so shares from either implementation
+// can be combined by the other.
+//
+// Shares are assigned the x coordinates 1 to parts in order, and all the
+// randomness of the polynomials is read from a caller-provided source, so a
+// split can be reproduced from a recorded seed. Field arithmetic runs in
+// constant time.
+package shamir
+
+import (
+	"fmt"
+	"io"
+)
+
+// ShareOverhead is the byte size overhead of each share, caused by the x
+// coordinate appended to it.
+const ShareOverhead = 1
+
+// CheckParameters returns an error if a secret cannot be split into parts
+// shares with the given threshold.
+func CheckParameters(parts, threshold int) error {
+	if parts < threshold {
+		return fmt.Errorf("parts cannot be less than threshold")
+	}
+	if parts > 255 {
+		return fmt.Errorf("parts cannot exceed 255")
+	}
+	if threshold < 2 {
+		return fmt.Errorf("threshold must be at least 2")
+	}
+	if threshold > 255 {
+		return fmt.Errorf("threshold cannot exceed 255")
+	}
+	return nil
+}
+
+// Split splits secret into parts shares, threshold of which are required to
+// reconstruct it. The parts and threshold must be at least 2 and less than 256.
+// The i-th share (from 0) has the x coordinate i+1. All randomness is read
+// from rand, which should be crypto/rand.Reader unless a deterministic split
+// is needed.
+func Split(secret []byte, parts, threshold int, rand io.Reader) ([][]byte, error) {
+	if err := CheckParameters(parts, threshold); err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("cannot split an empty secret")
+	}
+
+	out := make([][]byte, parts)
+	for i := range out {
+		out[i] = make([]byte, len(secret)+ShareOverhead)
+		out[i][len(secret)] = byte(i + 1)
+	}
+
+	// A new random polynomial is used for each byte of the secret, with the
+	// byte as its intercept
+	coefficients := make([]byte, threshold)
+	defer clear(coefficients)
+	for idx, val := range secret {
+		coefficients[0] = val
+		if _, err := io.ReadFull(rand, coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+		}
+		for i := range out {
+			out[i][idx] = evaluate(coefficients, byte(i+1))
+		}
+	}
+	return out, nil
+}
+
+// Combine reconstructs the secret from threshold or more shares created by
+// Split. Combining fewer shares than the threshold returns a wrong secret
+// without any error, so the result should be checked by other means.
+func Combine(parts [][]byte) ([]byte, error) {
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("less than two parts cannot be used to reconstruct the secret")
+	}
+
+	partLen := len(parts[0])
+	if partLen < 2 {
+		return nil, fmt.Errorf("parts must be at least two bytes")
+	}
+	xSamples := make([]byte, len(parts))
+	seen := map[byte]bool{}
+	for i, part := range parts {
+		if len(part) != partLen {
+			return nil, fmt.Errorf("all parts must be the same length")
+		}
+		x := part[partLen-1]
+		if x == 0 {
+			return nil, fmt.Errorf("invalid part with x coordinate 0")
+		}
+		if seen[x] {
+			return nil, fmt.Errorf("duplicate part detected")
+		}
+		seen[x] = true
+		xSamples[i] = x
+	}
+
+	// The Lagrange basis polynomials only depend on the x coordinates, so
+	// their values at 0 are the same for every byte of the secret
+	basis := make([]byte, len(parts))
+	for i := range parts {
+		basis[i] = 1
+		for j := range parts {
+			if i != j {
+				basis[i] = mult(basis[i], div(xSamples[j], add(xSamples[i], xSamples[j])))
+			}
+		}
+	}
+
+	secret := make([]byte, partLen-ShareOverhead)
+	for idx := range secret {
+		var val byte
+		for i, part := range parts {
+			val = add(val, mult(part[idx], basis[i]))
+		}
+		secret[idx] = val
+	}
+	return secret, nil
+}
+
+// evaluate returns the value at x of the polynomial with the given
+// coefficients, using Horner's method.
+func evaluate(coefficients []byte, x byte) byte {
+	degree := len(coefficients) - 1
+	out := coefficients[degree]
+	for i := degree - 1; i >= 0; i-- {
+		out = add(mult(out, x), coefficients[i])
+	}
+	return out
+}
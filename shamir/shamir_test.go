@@ -0,0 +1,192 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/entropy"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple, 32")
+	for _, tc := range []struct{ parts, threshold int }{{2, 2}, {3, 2}, {5, 3}, {255, 255}} {
+		shares, err := Split(secret, tc.parts, tc.threshold, rand.Reader)
+		require.NoError(t, err)
+		require.Len(t, shares, tc.parts)
+
+		for i, share := range shares {
+			require.Len(t, share, len(secret)+ShareOverhead)
+			assert.Equal(t, byte(i+1), share[len(secret)])
+		}
+
+		combined, err := Combine(shares[len(shares)-tc.threshold:])
+		require.NoError(t, err)
+		assert.Equal(t, secret, combined)
+
+		combined, err = Combine(shares[:tc.threshold])
+		require.NoError(t, err)
+		assert.Equal(t, secret, combined)
+
+		if tc.threshold > 2 {
+			combined, err = Combine(shares[:tc.threshold-1])
+			require.NoError(t, err)
+			assert.NotEqual(t, secret, combined)
+		}
+	}
+}
+
+// TestCombineVaultShares combines shares created by the Split function of
+// github.com/hashicorp/vault/shamir, which has random x coordinates.
+func TestCombineVaultShares(t *testing.T) {
+	testCases := []struct {
+		name      string
+		secret    string
+		threshold int
+		shares    []string
+	}{
+		{
+			name:      "3_of_5",
+			secret:    "d7a1c0b8f1e2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c",
+			threshold: 3,
+			shares: []string{
+				"cf76386907c92c7b944448f1db3d6e724a50e5d47bc55b67a8a27171da8fa87448",
+				"64003c3e77c5bb81163c2911215ef8cc3753ab4a46a7b5fb98f17c2d3bf5c9e3d1",
+				"8cbc1682691974ff1ebd099027d70cf76898b9eb95a21b6705b11b662716491a36",
+				"5da20668d0ac9cca0c3b095f75053ab6d4967781863d35d1b18f1444585d347950",
+				"223ff07b20f335414a3766b3712a18601e09b87860ade6dfd2703146d11a5d3902",
+			},
+		},
+		{
+			name:      "2_of_4",
+			secret:    hex.EncodeToString([]byte("0x0123456789")),
+			threshold: 2,
+			shares: []string{
+				"5d7e7e99e8300e9784530ee1d5",
+				"be1fef32358d889f66ae4aea77",
+				"5030d9fef2179a22faab06c174",
+				"67da85129c62aeeb3cccfd00a0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shares := make([][]byte, len(tc.shares))
+			for i, s := range tc.shares {
+				share, err := hex.DecodeString(s)
+				require.NoError(t, err)
+				shares[i] = share
+			}
+
+			// every combination of threshold shares recovers the secret
+			for mask := 0; mask < 1<<len(shares); mask++ {
+				var subset [][]byte
+				for i := range shares {
+					if mask&(1<<i) != 0 {
+						subset = append(subset, shares[i])
+					}
+				}
+				if len(subset) < tc.threshold {
+					continue
+				}
+				combined, err := Combine(subset)
+				require.NoError(t, err)
+				assert.Equal(t, tc.secret, hex.EncodeToString(combined))
+			}
+		})
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		parts  [][]byte
+		errMsg string
+	}{
+		{name: "single_part", parts: [][]byte{{1, 2}}, errMsg: "less than two parts cannot be used to reconstruct the secret"},
+		{name: "short_part", parts: [][]byte{{1}, {2}}, errMsg: "parts must be at least two bytes"},
+		{name: "different_lengths", parts: [][]byte{{1, 2}, {1, 2, 3}}, errMsg: "all parts must be the same length"},
+		{name: "duplicate_x", parts: [][]byte{{1, 2}, {3, 2}}, errMsg: "duplicate part detected"},
+		{name: "zero_x", parts: [][]byte{{1, 0}, {3, 2}}, errMsg: "invalid part with x coordinate 0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Combine(tc.parts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestSplitDeterministic(t *testing.T) {
+	secret, err := hex.DecodeString("00112233445566778899aabbccddeeff")
+	require.NoError(t, err)
+
+	shares, err := Split(secret, 3, 2, entropy.NewDRBG([]byte("shamir test vector")))
+	require.NoError(t, err)
+	again, err := Split(secret, 3, 2, entropy.NewDRBG([]byte("shamir test vector")))
+	require.NoError(t, err)
+	assert.Equal(t, shares, again)
+
+	// Vector for cross-implementation checks: the shares are {y..., x}, and
+	// each polynomial coefficient is read from the DRBG one at a time
+	hexShares := make([]string, len(shares))
+	for i, share := range shares {
+		hexShares[i] = hex.EncodeToString(share)
+	}
+	assert.Equal(t, []string{
+		"fac7abc3b5f4856e7003a960a1d34a0b01",
+		"efa62bc8bd0cbb4563b6ac1616c1bd0c02",
+		"1570a2384cad585c9b2cafcd7bcf19f803",
+	}, hexShares)
+}
+
+func TestSplitErrors(t *testing.T) {
+	testCases := []struct {
+		name             string
+		secret           []byte
+		parts, threshold int
+		errMsg           string
+	}{
+		{name: "threshold_greater_than_parts", secret: []byte{1}, parts: 2, threshold: 3, errMsg: "parts cannot be less than threshold"},
+		{name: "too_many_parts", secret: []byte{1}, parts: 256, threshold: 2, errMsg: "parts cannot exceed 255"},
+		{name: "threshold_too_low", secret: []byte{1}, parts: 2, threshold: 1, errMsg: "threshold must be at least 2"},
+		{name: "empty_secret", secret: nil, parts: 3, threshold: 2, errMsg: "cannot split an empty secret"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Split(tc.secret, tc.parts, tc.threshold, rand.Reader)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+
+	t.Run("short_rand", func(t *testing.T) {
+		_, err := Split([]byte("secret"), 3, 2, bytes.NewReader(make([]byte, 4)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to generate")
+	})
+}
+
+func TestInverse(t *testing.T) {
+	assert.Equal(t, byte(0), inverse(0))
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), mult(byte(a), inverse(byte(a))), "inverse of %d", a)
+	}
+}
+
+func TestMult(t *testing.T) {
+	// Examples from FIPS 197, section 4.2
+	assert.Equal(t, byte(0xc1), mult(0x57, 0x83))
+	assert.Equal(t, byte(0xfe), mult(0x57, 0x13))
+	for a := 0; a < 256; a++ {
+		assert.Equal(t, byte(0), mult(byte(a), 0))
+		assert.Equal(t, byte(a), mult(byte(a), 1))
+	}
+}
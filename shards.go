@@ -8,6 +8,6 @@ import (
 func main() {
 	if err := RunCLI(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
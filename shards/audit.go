@@ -0,0 +1,292 @@
+package shards
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/victorges/recovery-shards/model"
+)
+
+// ProblemKind is the kind of a problem found by Audit.
+type ProblemKind string
+
+const (
+	// ProblemUnreadable is a file that could not be read.
+	ProblemUnreadable ProblemKind = "unreadable"
+	// ProblemNotShares is a file without any share, which recover skips.
+	ProblemNotShares ProblemKind = "not_shares"
+	// ProblemInvalidShare is a share that cannot be read, like one with a
+	// typo or a bad checksum.
+	ProblemInvalidShare ProblemKind = "invalid_share"
+	// ProblemPermissions is a share file whose permissions are not 0600.
+	ProblemPermissions ProblemKind = "permissions"
+	// ProblemDuplicate is a share found in several files.
+	ProblemDuplicate ProblemKind = "duplicate"
+	// ProblemConflict is a pair of different shares with the same identifier.
+	ProblemConflict ProblemKind = "conflict"
+	// ProblemMixedSets is a share or a manifest of another share set.
+	ProblemMixedSets ProblemKind = "mixed_sets"
+	// ProblemManifest is a manifest that cannot be read.
+	ProblemManifest ProblemKind = "manifest"
+	// ProblemThreshold is a threshold that cannot be reached, or that is not
+	// known without a manifest, in which case the shares cannot be shown to
+	// be enough.
+	ProblemThreshold ProblemKind = "threshold"
+)
+
+// Problem is a problem found by Audit.
+type Problem struct {
+	Kind ProblemKind `json:"kind"`
+	// Blocking is whether the problem prevents the recovery, or is only a
+	// warning
+	Blocking bool     `json:"blocking"`
+	Message  string   `json:"message"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// AuditedFile is a file checked by Audit.
+type AuditedFile struct {
+	Path string      `json:"path"`
+	Mode fs.FileMode `json:"mode"`
+	// Shares lists the hex identifiers of the shares of the file
+	Shares []string `json:"shares"`
+}
+
+// AuditReport is the result of Audit.
+type AuditReport struct {
+	Files []AuditedFile
+	// Shares are the distinct shares found, with the files they were found in
+	Shares []FoundShare
+	// Manifest is the manifest of the share set, if known
+	Manifest *model.Manifest
+	// Usable is the number of distinct shares of the share set that can be
+	// used to recover, without conflicting copies or shares of other sets
+	Usable   int
+	Problems []Problem
+}
+
+// Go returns whether the shares can be recovered: no problem is blocking, and
+// the threshold is reached.
+func (r *AuditReport) Go() bool {
+	return !slices.ContainsFunc(r.Problems, func(p Problem) bool { return p.Blocking })
+}
+
+// AuditOptions are the options of Audit.
+type AuditOptions struct {
+	// IndexBase is the first decimal word index of shares written as word
+	// indices, 1 by default.
+	IndexBase int
+	// Recursive is whether the subdirectories of a directory are read too.
+	Recursive bool
+	// Manifest is the manifest of the share set. If nil, the manifests found
+	// next to the shares are used.
+	Manifest *model.Manifest
+}
+
+// audit holds the state of an Audit.
+type audit struct {
+	report    *AuditReport
+	index     map[string]int
+	conflicts map[string]bool
+	manifests []string
+}
+
+func (a *audit) problem(kind ProblemKind, blocking bool, files []string, format string, args ...any) {
+	a.report.Problems = append(a.report.Problems, Problem{Kind: kind, Blocking: blocking, Message: fmt.Sprintf(format, args...), Files: files})
+}
+
+// Audit checks the share files at the paths, which may be glob patterns like
+// for FileReader.Find, before a recovery. It reports unreadable files and files
+// without shares, damaged shares, share files with permissions other than
+// 0600, shares found in several files, conflicting copies of a share, shares
+// of different share sets, and whether the threshold can be reached. The
+// shares are never combined. An error is only returned for a path that does
+// not exist.
+func Audit(patterns []string, opts AuditOptions) (*AuditReport, error) {
+	if opts.IndexBase == 0 {
+		opts.IndexBase = 1
+	}
+	reader := &FileReader{IndexBase: opts.IndexBase, Recursive: opts.Recursive}
+	a := &audit{report: &AuditReport{}, index: make(map[string]int), conflicts: make(map[string]bool)}
+
+	for _, pattern := range patterns {
+		paths, _, err := expandPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read path: %w", err)
+			}
+			files, manifests, err := reader.pathFiles(path, info)
+			if err != nil {
+				return nil, err
+			}
+			for _, manifest := range manifests {
+				if !slices.Contains(a.manifests, manifest) {
+					a.manifests = append(a.manifests, manifest)
+				}
+			}
+			for _, file := range files {
+				a.checkFile(reader, file)
+			}
+		}
+	}
+
+	a.checkDuplicates()
+	a.report.Manifest = opts.Manifest
+	if a.report.Manifest == nil {
+		a.readManifests()
+	}
+	a.checkSets()
+	a.checkThreshold()
+	return a.report, nil
+}
+
+// checkFile checks the permissions of a file and reads its shares.
+func (a *audit) checkFile(reader *FileReader, path string) {
+	if slices.ContainsFunc(a.report.Files, func(f AuditedFile) bool { return f.Path == path }) {
+		return
+	}
+	file := AuditedFile{Path: path, Shares: []string{}}
+	if info, err := os.Stat(path); err == nil {
+		file.Mode = info.Mode().Perm()
+	}
+	a.report.Files = append(a.report.Files, file)
+	audited := &a.report.Files[len(a.report.Files)-1]
+
+	shares, err := reader.readFile(path)
+	var parseErr *ParseError
+	switch {
+	case errors.Is(err, ErrNoShares):
+		a.problem(ProblemNotShares, false, []string{path}, "%s holds no shares and is skipped", path)
+		return
+	case errors.As(err, &parseErr):
+		a.problem(ProblemInvalidShare, true, []string{path}, "%s: %v", path, err)
+		return
+	case err != nil:
+		a.problem(ProblemUnreadable, true, []string{path}, "%s cannot be read: %v", path, errors.Unwrap(err))
+		return
+	}
+
+	if file.Mode != 0600 {
+		a.problem(ProblemPermissions, false, []string{path}, "%s has permissions %04o, expected 0600", path, file.Mode)
+	}
+	for _, share := range shares {
+		id := fmt.Sprintf("%04x", share.Identifier)
+		audited.Shares = append(audited.Shares, id)
+		a.addShare(id, share, path)
+	}
+}
+
+// addShare adds a share found in a file, and reports it if it was already
+// found elsewhere.
+func (a *audit) addShare(id string, share model.MnemonicShare, path string) {
+	i, ok := a.index[id]
+	if !ok {
+		a.index[id] = len(a.report.Shares)
+		a.report.Shares = append(a.report.Shares, FoundShare{Share: share, Files: []string{path}})
+		return
+	}
+
+	existing := &a.report.Shares[i]
+	if existing.Share.Mnemonic != share.Mnemonic {
+		a.conflicts[id] = true
+		a.problem(ProblemConflict, true, []string{existing.Files[0], path}, "conflicting copies of share 0x%s in %s and %s", id, existing.Files[0], path)
+		return
+	}
+	if slices.Contains(existing.Files, path) {
+		a.problem(ProblemDuplicate, false, []string{path}, "share 0x%s is found several times in %s", id, path)
+		return
+	}
+	existing.Files = append(existing.Files, path)
+}
+
+// checkDuplicates reports the shares found in several files.
+func (a *audit) checkDuplicates() {
+	for _, found := range a.report.Shares {
+		if len(found.Files) > 1 {
+			a.problem(ProblemDuplicate, false, found.Files, "share 0x%04x is found in %s", found.Share.Identifier, strings.Join(found.Files, ", "))
+		}
+	}
+}
+
+// readManifests reads the manifests found next to the shares, which must all
+// be of the same share set.
+func (a *audit) readManifests() {
+	for _, path := range a.manifests {
+		manifest, err := ReadManifest(path)
+		switch {
+		case err != nil:
+			a.problem(ProblemManifest, true, []string{path}, "%s: %v", path, err)
+		case a.report.Manifest == nil:
+			a.report.Manifest = manifest
+		case manifest.SetID != a.report.Manifest.SetID:
+			a.problem(ProblemMixedSets, true, []string{path}, "%s is the manifest of share set %s, not %s", path, manifest.SetID, a.report.Manifest.SetID)
+		}
+	}
+}
+
+// checkSets reports the shares that are not part of the share set of the
+// manifest or, without one, of the same set as the first share, and counts the
+// usable shares. Shares of a split have distinct numbers, so a share with the
+// same number as another one is of a different split, even if it matches the
+// manifest or looks like the first share.
+func (a *audit) checkSets() {
+	var first *model.MnemonicShare
+	numbers := make(map[int]model.MnemonicShare)
+	for _, found := range a.report.Shares {
+		share := found.Share
+		id := fmt.Sprintf("%04x", share.Identifier)
+		if a.conflicts[id] {
+			continue
+		}
+
+		switch manifest := a.report.Manifest; {
+		case manifest != nil && !slices.Contains(manifest.Shares, id):
+			a.problem(ProblemMixedSets, true, found.Files, "share 0x%s is not part of share set %s", id, manifest.SetID)
+			continue
+		case manifest == nil && first == nil:
+			first = &share
+		case manifest == nil && !sameSet(*first, share):
+			a.problem(ProblemMixedSets, true, found.Files, "share 0x%s is not part of the same share set as share 0x%04x", id, first.Identifier)
+			continue
+		}
+		if other, ok := numbers[share.Number()]; ok && share.Number() > 0 {
+			a.problem(ProblemMixedSets, true, found.Files, "share 0x%s has the same number %d as share 0x%04x, so they are of different share sets", id, share.Number(), other.Identifier)
+			continue
+		}
+		numbers[share.Number()] = share
+		a.report.Usable++
+	}
+}
+
+// sameSet returns whether two shares may be of the same split, which have the
+// same number of words and total.
+func sameSet(a, b model.MnemonicShare) bool {
+	sameTotal := a.Total == 0 || b.Total == 0 || a.Total == b.Total
+	return sameTotal && len(strings.Fields(a.Mnemonic)) == len(strings.Fields(b.Mnemonic))
+}
+
+// checkThreshold reports whether there are enough usable shares to recover.
+func (a *audit) checkThreshold() {
+	usable := a.report.Usable
+	if manifest := a.report.Manifest; manifest != nil {
+		if usable < manifest.Threshold {
+			a.problem(ProblemThreshold, true, nil, "share set %s requires %d shares to recover, found %d usable", manifest.SetID, manifest.Threshold, usable)
+		}
+		return
+	}
+	if usable < 2 {
+		a.problem(ProblemThreshold, true, nil, "at least 2 shares are required to recover, found %d usable", usable)
+		return
+	}
+	// the shares may be too few for the threshold, which is only known from
+	// the manifest
+	a.problem(ProblemThreshold, true, nil, "the threshold is unknown without a manifest, the %d usable shares can only be recovered if it is at most %d", usable, usable)
+}
@@ -0,0 +1,174 @@
+package shards
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+// problemKinds returns the kinds of the problems of the report, with whether
+// they are blocking.
+func problemKinds(report *AuditReport) map[ProblemKind]bool {
+	kinds := make(map[ProblemKind]bool)
+	for _, problem := range report.Problems {
+		kinds[problem.Kind] = kinds[problem.Kind] || problem.Blocking
+	}
+	return kinds
+}
+
+func TestAudit(t *testing.T) {
+	secret, err := seed.Parse(seed.BIP39, testMnemonic)
+	require.NoError(t, err)
+	splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2})
+	require.NoError(t, err)
+	split, err := splitter.Split(secret)
+	require.NoError(t, err)
+
+	// writeSet writes the shares and the manifest of the split to a new
+	// directory
+	writeSet := func(t *testing.T, shares []model.MnemonicShare) (string, []string) {
+		dir := t.TempDir() + "/"
+		files, err := WriteShares(shares, dir)
+		require.NoError(t, err)
+		_, err = WriteManifest(split.Manifest, dir)
+		require.NoError(t, err)
+		return dir, files
+	}
+
+	dir, files := writeSet(t, split.Shares)
+	report, err := Audit([]string{dir}, AuditOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Go())
+	assert.Empty(t, report.Problems)
+	assert.Len(t, report.Files, 3)
+	assert.Equal(t, split.Manifest.SetID, report.Manifest.SetID)
+	assert.Equal(t, 3, report.Usable)
+
+	t.Run("warnings", func(t *testing.T) {
+		dir, files := writeSet(t, split.Shares)
+		require.NoError(t, os.Chmod(files[0], 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Shares\n\nKeep these safe.\n"), 0600))
+		_, err := WriteShares(split.Shares[1:2], filepath.Join(dir, "copy.txt"))
+		require.NoError(t, err)
+
+		report, err := Audit([]string{dir}, AuditOptions{})
+		require.NoError(t, err)
+		assert.True(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemPermissions: false, ProblemNotShares: false, ProblemDuplicate: false}, problemKinds(report))
+		assert.Equal(t, 3, report.Usable)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		// flipping the same bits of two bytes keeps the check byte
+		data, err := split.Shares[0].ToShamir()
+		require.NoError(t, err)
+		data[0], data[1] = data[0]^0xff, data[1]^0xff
+		other, err := model.NewMnemonicShareFromShamir(data)
+		require.NoError(t, err)
+		other.Total = 3
+		conflict := filepath.Join(t.TempDir(), "other.txt")
+		_, err = WriteShares([]model.MnemonicShare{other}, conflict)
+		require.NoError(t, err)
+
+		report, err := Audit([]string{files[0], files[1], conflict}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemConflict: true, ProblemThreshold: true}, problemKinds(report))
+		assert.Equal(t, 1, report.Usable)
+	})
+
+	t.Run("mixed_sets", func(t *testing.T) {
+		otherSplit, err := splitter.Split(secret)
+		require.NoError(t, err)
+		otherDir := t.TempDir()
+		otherFiles, err := WriteShares(otherSplit.Shares[2:], otherDir+"/")
+		require.NoError(t, err)
+
+		report, err := Audit([]string{dir, otherFiles[0]}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemMixedSets: true}, problemKinds(report))
+		assert.Equal(t, 3, report.Usable)
+
+		_, err = WriteManifest(otherSplit.Manifest, otherDir+"/")
+		require.NoError(t, err)
+		report, err = Audit([]string{dir, otherDir}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemMixedSets: true}, problemKinds(report))
+	})
+
+	t.Run("same_number", func(t *testing.T) {
+		// share 1 of two splits, with different identifiers so that they do
+		// not conflict
+		second := split
+		for bytes.Equal(second.Shares[0].Identifier, split.Shares[0].Identifier) {
+			var err error
+			second, err = splitter.Split(secret)
+			require.NoError(t, err)
+		}
+		dir := t.TempDir()
+		for name, share := range map[string]model.MnemonicShare{
+			"a_share_1.txt": split.Shares[0],
+			"b_share_1.txt": second.Shares[0],
+			"b_share_2.txt": second.Shares[1],
+		} {
+			_, err := WriteShares([]model.MnemonicShare{share}, filepath.Join(dir, name))
+			require.NoError(t, err)
+		}
+
+		report, err := Audit([]string{dir}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemMixedSets: true, ProblemThreshold: true}, problemKinds(report))
+		assert.Equal(t, 2, report.Usable)
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		dir, _ := writeSet(t, split.Shares[:1])
+		report, err := Audit([]string{dir}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemThreshold: true}, problemKinds(report))
+
+		// without a manifest, the threshold is unknown, so even 2 shares of a
+		// 2 of 3 split are not known to be enough
+		report, err = Audit(files[:2], AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemThreshold: true}, problemKinds(report))
+		assert.Contains(t, report.Problems[0].Message, "can only be recovered if it is at most 2")
+
+		report, err = Audit(files[:2], AuditOptions{Manifest: split.Manifest})
+		require.NoError(t, err)
+		assert.True(t, report.Go())
+		assert.Empty(t, report.Problems)
+	})
+
+	t.Run("invalid_share", func(t *testing.T) {
+		dir, files := writeSet(t, split.Shares)
+		content, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		words := strings.Fields(split.Shares[0].Mnemonic)
+		damaged := strings.Replace(string(content), words[3], "zzzz", 1)
+		require.NoError(t, os.WriteFile(files[0], []byte(damaged), 0600))
+
+		report, err := Audit([]string{dir}, AuditOptions{})
+		require.NoError(t, err)
+		assert.False(t, report.Go())
+		assert.Equal(t, map[ProblemKind]bool{ProblemInvalidShare: true}, problemKinds(report))
+		assert.Equal(t, 2, report.Usable)
+	})
+
+	t.Run("missing_path", func(t *testing.T) {
+		_, err := Audit([]string{filepath.Join(dir, "missing")}, AuditOptions{})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,189 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/secure"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/wordcode"
+)
+
+// SchemaVersion is the version of the JSON documents written by the command
+// line tool with -output json, whose shares can be read by a Decoder.
+const SchemaVersion = 1
+
+// ParseError is returned by a Decoder for a line that is not a valid share.
+type ParseError struct {
+	// Line is the 1-based number of the line in the input.
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Encoder writes shares in the text format of share files, one per line, like
+// "Share 3 of 5 (0x03c4): word1 word2 ...", preceded by a comment with the
+// custodian label of the share if it has one.
+type Encoder struct {
+	w io.Writer
+	// Format is how the words are written, full words by default. The short
+	// formats are meant for cards and metal plates, and are all read back by
+	// a Decoder.
+	Format wordcode.Format
+	// IndexBase is the first decimal word index of the Index format, 1 by
+	// default.
+	IndexBase int
+}
+
+// NewEncoder creates an Encoder that writes full words to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, Format: wordcode.Words, IndexBase: 1}
+}
+
+// Encode writes a share.
+func (e *Encoder) Encode(share model.MnemonicShare) error {
+	mnemonic, err := wordcode.EncodePhrase(share.Mnemonic, e.Format, e.IndexBase)
+	if err != nil {
+		return err
+	}
+	share.Mnemonic = mnemonic
+	if share.Label != "" {
+		if _, err := fmt.Fprintf(e.w, "%s %s\n", model.LabelComment, share.Label); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(e.w, share)
+	return err
+}
+
+// Decoder reads shares written by an Encoder, or in JSON.
+type Decoder struct {
+	r io.Reader
+	// IndexBase is the first decimal word index of shares written as word
+	// indices, 1 by default.
+	IndexBase int
+	// SkipChecksums is whether the shares of the text format are created with
+	// model.NewUncheckedShare, so that the ones with a wrong checksum can
+	// still be inspected.
+	SkipChecksums bool
+}
+
+// NewDecoder creates a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, IndexBase: 1}
+}
+
+// Decode reads all the shares of the input. In the text format, the words may
+// be written in any of the forms accepted by wordcode.Expand, shares may also
+// be in the legacy "0x5954: words..." format, and lines starting with # are
+// comments. Shares are pulled out of any other text, and their words may be
+// spread over several lines, like in a numbered list. A JSON input may be the
+// JSON output of the command line tool, a list of shares or a single share.
+// ErrNoShares is returned if the input holds no share.
+func (d *Decoder) Decode() ([]model.MnemonicShare, error) {
+	content, err := io.ReadAll(d.r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shares: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(content); (bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("["))) && json.Valid(trimmed) {
+		return decodeJSON(trimmed)
+	}
+	return d.decodeText(string(content))
+}
+
+// decodeJSON reads the shares of a JSON input: the JSON output of split, a
+// list of shares or a single share.
+func decodeJSON(content []byte) ([]model.MnemonicShare, error) {
+	if content[0] == '[' {
+		var shares []model.MnemonicShare
+		if err := json.Unmarshal(content, &shares); err != nil {
+			return nil, fmt.Errorf("invalid JSON share list: %w", err)
+		}
+		return shares, nil
+	}
+
+	var doc struct {
+		SchemaVersion int `json:"schema_version"`
+		Result        *struct {
+			Shares []model.MnemonicShare `json:"shares"`
+		} `json:"result"`
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON share file: %w", err)
+	}
+	switch {
+	case doc.SchemaVersion > SchemaVersion:
+		return nil, fmt.Errorf("unsupported JSON schema version: %d", doc.SchemaVersion)
+	case doc.Result != nil && len(doc.Result.Shares) > 0:
+		return doc.Result.Shares, nil
+	case doc.Identifier != "":
+		var share model.MnemonicShare
+		if err := json.Unmarshal(content, &share); err != nil {
+			return nil, fmt.Errorf("invalid JSON share: %w", err)
+		}
+		return []model.MnemonicShare{share}, nil
+	}
+	return nil, fmt.Errorf("%w in JSON file", ErrNoShares)
+}
+
+// ReadSecret reads a secret of the given type, or of the type detected from
+// its text for seed.Auto. The words of a BIP39 mnemonic may be written in any
+// of the forms accepted by wordcode.Expand, with decimal word indices starting
+// at indexBase.
+func ReadSecret(r io.Reader, seedType seed.Type, indexBase int) (seed.Secret, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return seed.Secret{}, fmt.Errorf("failed to read secret: %w", err)
+	}
+	defer secure.Wipe(content)
+
+	// seed.Detect and seed.Parse take the text as a string, which cannot be
+	// wiped, so it is only copied into one
+	text := string(content)
+	if seedType == seed.Auto {
+		seedType = seed.Detect(text)
+	}
+	if seedType != seed.BIP39 {
+		return seed.Parse(seedType, text)
+	}
+
+	identifier, mnemonic, err := mnemonicLine(text, indexBase)
+	if err != nil {
+		return seed.Secret{}, err
+	} else if identifier != "" {
+		return seed.Secret{}, fmt.Errorf("unexpected identifier in mnemonic file: %s", identifier)
+	}
+	return seed.Parse(seed.BIP39, mnemonic)
+}
+
+// mnemonicLine reads a phrase, optionally preceded by a share identifier,
+// expanding the short forms of its words with decimal indices starting at base.
+// The words may be separated by commas and numbered like a list.
+func mnemonicLine(content string, base int) (string, string, error) {
+	words := textTokens(content)
+	identifier := ""
+	if len(words) > 0 && model.IsWordCountValid(len(words)-1) {
+		identifier = words[0]
+		words = words[1:]
+	} else if !model.IsWordCountValid(len(words)) {
+		return "", "", fmt.Errorf("mnemonic must contain 12, 15, 18, 21 or 24 words, after any multiple of 24 words for longer shares, got %d", len(words))
+	}
+
+	mnemonic, err := wordcode.ExpandPhrase(strings.Join(words, " "), base)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid word in mnemonic: %w", err)
+	}
+	return identifier, mnemonic, nil
+}
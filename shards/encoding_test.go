@@ -0,0 +1,164 @@
+package shards
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/wordcode"
+)
+
+func testShares(t *testing.T) []model.MnemonicShare {
+	t.Helper()
+	secret, err := seed.Parse(seed.BIP39, testMnemonic)
+	require.NoError(t, err)
+	splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2, Labels: []string{"Alice", "Bob", "Carol"}})
+	require.NoError(t, err)
+	split, err := splitter.Split(secret)
+	require.NoError(t, err)
+	return split.Shares
+}
+
+func TestEncoding(t *testing.T) {
+	shares := testShares(t)
+
+	for _, format := range []wordcode.Format{wordcode.Words, wordcode.Prefix, wordcode.Index, wordcode.Dots} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder := NewEncoder(&buf)
+			encoder.Format = format
+			for _, share := range shares {
+				require.NoError(t, encoder.Encode(share))
+			}
+			assert.Contains(t, buf.String(), "# Custodian: Bob\nShare 2 of 3 (0x")
+
+			decoded, err := NewDecoder(&buf).Decode()
+			require.NoError(t, err)
+			assert.Equal(t, shares, decoded)
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		list, err := json.Marshal(shares)
+		require.NoError(t, err)
+		decoded, err := NewDecoder(bytes.NewReader(list)).Decode()
+		require.NoError(t, err)
+		assert.Equal(t, shares, decoded)
+
+		single, err := json.Marshal(shares[0])
+		require.NoError(t, err)
+		decoded, err = NewDecoder(bytes.NewReader(single)).Decode()
+		require.NoError(t, err)
+		assert.Equal(t, shares[:1], decoded)
+
+		doc := `{"schema_version": 1, "command": "split", "result": {"shares": ` + string(list) + `}}`
+		decoded, err = NewDecoder(strings.NewReader(doc)).Decode()
+		require.NoError(t, err)
+		assert.Equal(t, shares, decoded)
+
+		_, err = NewDecoder(strings.NewReader(`{"schema_version": 2}`)).Decode()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported JSON schema version: 2")
+	})
+
+	t.Run("parse_error", func(t *testing.T) {
+		input := "# notes\n\n" + shares[0].String() + "\n0x1234: goose apple ecology\n"
+		_, err := NewDecoder(strings.NewReader(input)).Decode()
+		require.Error(t, err)
+
+		var parseErr *ParseError
+		require.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, 4, parseErr.Line)
+		assert.Contains(t, err.Error(), "line 4: invalid mnemonic line")
+	})
+}
+
+func TestDecodeNoisyText(t *testing.T) {
+	shares := testShares(t)
+	words := func(share model.MnemonicShare) []string { return strings.Fields(share.Mnemonic) }
+
+	var numbered strings.Builder
+	for i, word := range words(shares[1]) {
+		fmt.Fprintf(&numbered, "%d. %s\n", i+1, strings.ToUpper(word[:1])+word[1:])
+	}
+	input := strings.Join([]string{
+		"Generated 3 shares with a 2-out-of-3 threshold.",
+		"Shares:",
+		"# Custodian: Alice",
+		shares[0].String(),
+		"",
+		"## Notes",
+		"Share 1 of 3 (0x" + hex.EncodeToString(shares[0].Identifier) + ") is kept in the bank box.",
+		"",
+		fmt.Sprintf("**Share 2 of 3 (0x%04x):**", shares[1].Identifier),
+		numbered.String(),
+		fmt.Sprintf("- 0x%04x: %s", shares[2].Identifier, strings.Join(words(shares[2]), ", ")),
+		"Keep these apart.",
+	}, "\n")
+
+	decoded, err := NewDecoder(strings.NewReader(input)).Decode()
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+	assert.Equal(t, "Alice", decoded[0].Label)
+	for i := range shares {
+		assert.Equal(t, shares[i].Identifier, decoded[i].Identifier)
+		assert.Equal(t, shares[i].Mnemonic, decoded[i].Mnemonic)
+	}
+
+	t.Run("invalid_word_line", func(t *testing.T) {
+		lines := strings.Split(numbered.String(), "\n")
+		lines[6] = "7. bitcoin"
+		input := fmt.Sprintf("Share 2 of 3 (0x%04x):\n%s", shares[1].Identifier, strings.Join(lines, "\n"))
+		_, err := NewDecoder(strings.NewReader(input)).Decode()
+		var parseErr *ParseError
+		require.ErrorAs(t, err, &parseErr)
+		assert.Equal(t, 8, parseErr.Line)
+		var invalid *model.ErrInvalidWord
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, 7, invalid.Position)
+	})
+
+	t.Run("no_shares", func(t *testing.T) {
+		_, err := NewDecoder(strings.NewReader("# Shares\n\nThe shares are in the bank.\n")).Decode()
+		assert.ErrorIs(t, err, ErrNoShares)
+
+		_, err = NewDecoder(strings.NewReader(`{"name": "notes"}`)).Decode()
+		assert.ErrorIs(t, err, ErrNoShares)
+
+		_, err = NewDecoder(strings.NewReader("Phrase:\n" + testMnemonic)).Decode()
+		var parseErr *ParseError
+		require.ErrorAs(t, err, &parseErr)
+		assert.Equal(t, 2, parseErr.Line)
+		assert.Contains(t, err.Error(), "share file must contain identifiers")
+	})
+}
+
+func TestReadSecret(t *testing.T) {
+	secret, err := ReadSecret(strings.NewReader("goos appl ecol ill redu poem wish oliv guit heal run chim limb vill nice dism razo meat prop try tale towa clev cher\n"), seed.Auto, 1)
+	require.NoError(t, err)
+	text, err := secret.Text()
+	require.NoError(t, err)
+	assert.Equal(t, testMnemonic, text)
+
+	_, err = ReadSecret(strings.NewReader("0x1234 "+testMnemonic), seed.BIP39, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected identifier in mnemonic file")
+
+	var numbered strings.Builder
+	for i, word := range strings.Fields(testMnemonic) {
+		fmt.Fprintf(&numbered, "%d) %s,\n", i+1, word)
+	}
+	secret, err = ReadSecret(strings.NewReader(numbered.String()), seed.Auto, 1)
+	require.NoError(t, err)
+	text, err = secret.Text()
+	require.NoError(t, err)
+	assert.Equal(t, testMnemonic, text)
+}
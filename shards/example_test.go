@@ -0,0 +1,47 @@
+package shards_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shards"
+)
+
+func Example() {
+	secret, err := seed.Parse(seed.BIP39, "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry")
+	if err != nil {
+		panic(err)
+	}
+	defer secret.Wipe()
+
+	splitter, err := shards.NewSplitter(shards.SplitOptions{Total: 3, Threshold: 2})
+	if err != nil {
+		panic(err)
+	}
+	split, err := splitter.Split(secret)
+	if err != nil {
+		panic(err)
+	}
+
+	// write two of the shares and read them back
+	var buf bytes.Buffer
+	encoder := shards.NewEncoder(&buf)
+	for _, share := range split.Shares[:2] {
+		if err := encoder.Encode(share); err != nil {
+			panic(err)
+		}
+	}
+	shares, err := shards.NewDecoder(&buf).Decode()
+	if err != nil {
+		panic(err)
+	}
+
+	recovered, err := shards.NewRecoverer(shards.RecoverOptions{Manifest: split.Manifest}).Recover(shares)
+	if err != nil {
+		panic(err)
+	}
+	defer recovered.Wipe()
+	fmt.Println(recovered.Type, split.Manifest.Fingerprint)
+	// Output: bip39 5de26f7b
+}
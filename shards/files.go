@@ -0,0 +1,349 @@
+package shards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/victorges/recovery-shards/model"
+)
+
+// FileReader reads the shares of share files and directories with a Decoder.
+type FileReader struct {
+	// IndexBase is the first decimal word index of shares written as word
+	// indices, 1 by default.
+	IndexBase int
+	// Recursive is whether the subdirectories of a directory are read too.
+	Recursive bool
+	// SkipChecksums is passed on to the Decoder of each file.
+	SkipChecksums bool
+	// Skipped, if set, is called for each file of a directory that is skipped
+	// because no share was found in it, like a README or a photo.
+	Skipped func(path string, err error)
+}
+
+// NewFileReader creates a FileReader for shares with decimal word indices
+// starting at 1.
+func NewFileReader() *FileReader {
+	return &FileReader{IndexBase: 1}
+}
+
+// ReadShares reads the shares of a share file, or of all the files of a
+// directory except manifests, with a Decoder. Decimal word indices start at
+// indexBase. Files of a directory without any share are skipped.
+func ReadShares(path string, indexBase int) ([]model.MnemonicShare, error) {
+	reader := NewFileReader()
+	reader.IndexBase = indexBase
+	return reader.Read(path)
+}
+
+// Read reads the shares of a share file, or of all the files of a directory
+// except manifests. ErrNoShares is returned for a file without any share, but
+// such files of a directory are skipped.
+func (r *FileReader) Read(path string) ([]model.MnemonicShare, error) {
+	shares := make([]model.MnemonicShare, 0)
+	err := r.read(path, false, func(_ string, found []model.MnemonicShare) error {
+		shares = append(shares, found...)
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// FoundShare is a share found by FileReader.Find, with every file it was found
+// in.
+type FoundShare struct {
+	Share model.MnemonicShare
+	Files []string
+}
+
+// Found holds what FileReader.Find found.
+type Found struct {
+	// Shares are the distinct shares found, in the order they were first
+	// found in.
+	Shares []FoundShare
+	// Manifests are the manifest files found in the directories read, or
+	// next to the share files read.
+	Manifests []string
+}
+
+// MnemonicShares returns the distinct shares found.
+func (f *Found) MnemonicShares() []model.MnemonicShare {
+	shares := make([]model.MnemonicShare, len(f.Shares))
+	for i, found := range f.Shares {
+		shares[i] = found.Share
+	}
+	return shares
+}
+
+// Find reads the shares of several paths, like the copies of the shares on
+// several USB sticks. Each path may be a glob pattern, like "/media/*/shares",
+// whose matching files are skipped if they have no share, like the files of a
+// directory. A share found in several files is only returned once, and an
+// error is returned if different shares have the same identifier.
+func (r *FileReader) Find(patterns []string) (*Found, error) {
+	found := &Found{}
+	index := make(map[string]int)
+	add := func(file string, shares []model.MnemonicShare) error {
+		for _, share := range shares {
+			id := fmt.Sprintf("%04x", share.Identifier)
+			i, ok := index[id]
+			if !ok {
+				index[id] = len(found.Shares)
+				found.Shares = append(found.Shares, FoundShare{Share: share, Files: []string{file}})
+				continue
+			}
+
+			existing := &found.Shares[i]
+			if existing.Share.Mnemonic != share.Mnemonic {
+				return fmt.Errorf("conflicting copies of share 0x%s in %s and %s", id, existing.Files[0], file)
+			}
+			if existing.Share.Label == "" {
+				existing.Share.Label = share.Label
+			}
+			if !slices.Contains(existing.Files, file) {
+				existing.Files = append(existing.Files, file)
+			}
+		}
+		return nil
+	}
+	addManifest := func(file string) {
+		if !slices.Contains(found.Manifests, file) {
+			found.Manifests = append(found.Manifests, file)
+		}
+	}
+
+	for _, pattern := range patterns {
+		paths, glob, err := expandPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if err := r.read(path, glob, add, addManifest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return found, nil
+}
+
+// expandPattern returns the paths matching a glob pattern, and whether it is
+// one, or the path itself otherwise.
+func expandPattern(pattern string) (paths []string, glob bool, err error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, false, nil
+	}
+	if paths, err = filepath.Glob(pattern); err != nil {
+		return nil, true, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	} else if len(paths) == 0 {
+		return nil, true, fmt.Errorf("no files match %s", pattern)
+	}
+	return paths, true, nil
+}
+
+// read calls found with the shares of the share file at path, or of each file
+// of the directory at path, and manifest with the manifest files next to them.
+// Files without any share are skipped in a directory, or if skip is set.
+func (r *FileReader) read(path string, skip bool, found func(file string, shares []model.MnemonicShare) error, manifest func(file string)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read path: %w", err)
+	}
+	files, manifests, err := r.pathFiles(path, info)
+	if err != nil {
+		return err
+	}
+	skip = skip || info.IsDir()
+	if manifest != nil {
+		for _, file := range manifests {
+			manifest(file)
+		}
+	}
+
+	for _, file := range files {
+		shares, err := r.readFile(file)
+		if skip && errors.Is(err, ErrNoShares) {
+			if r.Skipped != nil {
+				r.Skipped(file, err)
+			}
+			continue
+		} else if err != nil {
+			if info.IsDir() {
+				name, _ := filepath.Rel(path, file)
+				return fmt.Errorf("error reading %s: %w", name, err)
+			}
+			return err
+		}
+		if err := found(file, shares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *FileReader) readFile(path string) ([]model.MnemonicShare, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read share file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := NewDecoder(file)
+	decoder.IndexBase, decoder.SkipChecksums = r.IndexBase, r.SkipChecksums
+	return decoder.Decode()
+}
+
+// pathFiles returns the share and manifest files at path: the files of a
+// directory, or a single file along with the manifest next to it.
+func (r *FileReader) pathFiles(path string, info fs.FileInfo) (files, manifests []string, err error) {
+	switch {
+	case info.IsDir():
+		return r.directoryFiles(path)
+	case IsManifestFile(filepath.Base(path)):
+		return nil, []string{path}, nil
+	}
+	if manifestPath := ManifestPath(path, false); fileExists(manifestPath) {
+		manifests = append(manifests, manifestPath)
+	}
+	return []string{path}, manifests, nil
+}
+
+// directoryFiles returns the files of the directory, and of its
+// subdirectories if Recursive is set, split into share and manifest files.
+func (r *FileReader) directoryFiles(directory string) (files, manifests []string, err error) {
+	err = filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case entry.IsDir():
+			if path != directory && !r.Recursive {
+				return filepath.SkipDir
+			}
+		case IsManifestFile(entry.Name()):
+			manifests = append(manifests, path)
+		case entry.Type().IsRegular() || entry.Type()&fs.ModeSymlink != 0:
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	return files, manifests, nil
+}
+
+// fileExists returns whether there is a file at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isDirPath returns whether path is an existing directory or ends with a path
+// separator.
+func isDirPath(path string) bool {
+	if info, err := os.Stat(path); err == nil {
+		return info.IsDir()
+	}
+	return strings.HasSuffix(path, "/") || strings.HasSuffix(path, "\\")
+}
+
+// WriteShares saves the shares with full words and permissions 0600, and
+// returns the files written. If outputPath is a directory or ends with a path
+// separator, each share is written to its own file named after
+// MnemonicShare.FileName, and otherwise all of them are written to the file at
+// outputPath.
+func WriteShares(shares []model.MnemonicShare, outputPath string) ([]string, error) {
+	if isDirPath(outputPath) {
+		dirPath := strings.TrimRight(outputPath, "/\\")
+		if err := os.MkdirAll(dirPath, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		files := make([]string, len(shares))
+		for i, share := range shares {
+			files[i] = filepath.Join(dirPath, share.FileName())
+			if err := writeShareFile(files[i], share); err != nil {
+				return nil, fmt.Errorf("failed to write share file: %w", err)
+			}
+		}
+		return files, nil
+	}
+
+	if err := writeShareFile(outputPath, shares...); err != nil {
+		return nil, fmt.Errorf("failed to write shares file: %w", err)
+	}
+	return []string{outputPath}, nil
+}
+
+// writeShareFile writes the shares to a new file at path, replacing any
+// existing one.
+func writeShareFile(path string, shares ...model.MnemonicShare) error {
+	var content strings.Builder
+	encoder := NewEncoder(&content)
+	for _, share := range shares {
+		if err := encoder.Encode(share); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(content.String()), 0600)
+}
+
+// ManifestPath returns the path of the manifest for shares written to or read
+// from sharesPath: a manifest.json file inside a directory, or a file named
+// after a single shares file, like shares.manifest.json for shares.txt.
+func ManifestPath(sharesPath string, isDir bool) string {
+	if isDir {
+		return filepath.Join(strings.TrimRight(sharesPath, "/\\"), model.ManifestFileName)
+	}
+	return strings.TrimSuffix(sharesPath, filepath.Ext(sharesPath)) + ".manifest.json"
+}
+
+// IsManifestFile returns whether a file name is the one of a manifest.
+func IsManifestFile(name string) bool {
+	return name == model.ManifestFileName || strings.HasSuffix(name, ".manifest.json")
+}
+
+// WriteManifest saves the manifest next to the shares saved to sharesPath by
+// WriteShares, and returns the path of the manifest file.
+func WriteManifest(manifest *model.Manifest, sharesPath string) (string, error) {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	path := ManifestPath(sharesPath, isDirPath(sharesPath))
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to write manifest file: %w", err)
+	}
+	return path, nil
+}
+
+// ReadManifest reads the manifest file at path.
+func ReadManifest(path string) (*model.Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return model.ParseManifest(content)
+}
+
+// FindManifest reads the manifest next to the shares at sharesPath, or returns
+// nil if there is none.
+func FindManifest(sharesPath string) (*model.Manifest, error) {
+	info, err := os.Stat(sharesPath)
+	if err != nil {
+		return nil, nil
+	}
+	path := ManifestPath(sharesPath, info.IsDir())
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return ReadManifest(path)
+}
@@ -0,0 +1,154 @@
+package shards
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+func TestFiles(t *testing.T) {
+	secret, err := seed.Parse(seed.BIP39, testMnemonic)
+	require.NoError(t, err)
+	splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2})
+	require.NoError(t, err)
+	split, err := splitter.Split(secret)
+	require.NoError(t, err)
+	testDir := t.TempDir()
+
+	t.Run("directory", func(t *testing.T) {
+		dir := filepath.Join(testDir, "shares") + "/"
+		files, err := WriteShares(split.Shares, dir)
+		require.NoError(t, err)
+		require.Len(t, files, 3)
+		assert.Equal(t, filepath.Join(testDir, "shares", "share_2_of_3.txt"), files[1])
+		info, err := os.Stat(files[1])
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		manifestFile, err := WriteManifest(split.Manifest, dir)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(testDir, "shares", "manifest.json"), manifestFile)
+
+		shares, err := ReadShares(dir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, split.Shares, shares)
+		manifest, err := FindManifest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, split.Manifest, manifest)
+	})
+
+	t.Run("single_file", func(t *testing.T) {
+		path := filepath.Join(testDir, "shares.txt")
+		files, err := WriteShares(split.Shares, path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{path}, files)
+
+		manifestFile, err := WriteManifest(split.Manifest, path)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(testDir, "shares.manifest.json"), manifestFile)
+
+		shares, err := ReadShares(path, 1)
+		require.NoError(t, err)
+		assert.Equal(t, split.Shares, shares)
+		manifest, err := FindManifest(path)
+		require.NoError(t, err)
+		assert.Equal(t, split.Manifest, manifest)
+	})
+
+	t.Run("unrelated_files", func(t *testing.T) {
+		dir := filepath.Join(testDir, "notes")
+		_, err := WriteShares(split.Shares[:2], dir+"/")
+		require.NoError(t, err)
+		readme := filepath.Join(dir, "README.md")
+		require.NoError(t, os.WriteFile(readme, []byte("# Shares\n\nShare 3 of 3 is kept by Carol.\n"), 0600))
+
+		reader := NewFileReader()
+		var skipped []string
+		reader.Skipped = func(path string, err error) {
+			assert.ErrorIs(t, err, ErrNoShares)
+			skipped = append(skipped, path)
+		}
+		shares, err := reader.Read(dir)
+		require.NoError(t, err)
+		assert.Equal(t, split.Shares[:2], shares)
+		assert.Equal(t, []string{readme}, skipped)
+
+		_, err = reader.Read(readme)
+		assert.ErrorIs(t, err, ErrNoShares)
+	})
+
+	t.Run("no_manifest", func(t *testing.T) {
+		manifest, err := FindManifest(filepath.Join(testDir, "missing"))
+		require.NoError(t, err)
+		assert.Nil(t, manifest)
+	})
+}
+
+func TestFind(t *testing.T) {
+	secret, err := seed.Parse(seed.BIP39, testMnemonic)
+	require.NoError(t, err)
+	splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2, Labels: []string{"Alice", "Bob", "Carol"}})
+	require.NoError(t, err)
+	split, err := splitter.Split(secret)
+	require.NoError(t, err)
+
+	// each custodian's USB stick holds their share and a copy of the
+	// manifest, and the first one also a copy of the second share
+	media := t.TempDir()
+	sticks := []string{filepath.Join(media, "usb1"), filepath.Join(media, "usb2"), filepath.Join(media, "usb3")}
+	var files []string
+	for i, stick := range sticks {
+		dir := filepath.Join(stick, "custodian") + "/"
+		written, err := WriteShares(split.Shares[i:i+1], dir)
+		require.NoError(t, err)
+		files = append(files, written...)
+		_, err = WriteManifest(split.Manifest, dir)
+		require.NoError(t, err)
+	}
+	copied, err := WriteShares(split.Shares[1:2], filepath.Join(sticks[0], "spare.txt"))
+	require.NoError(t, err)
+
+	reader := NewFileReader()
+	reader.Recursive = true
+	found, err := reader.Find([]string{filepath.Join(media, "usb*"), sticks[1]})
+	require.NoError(t, err)
+	assert.Equal(t, split.Shares, found.MnemonicShares())
+	assert.Equal(t, []string{files[0]}, found.Shares[0].Files)
+	assert.Equal(t, []string{copied[0], files[1]}, found.Shares[1].Files)
+	assert.Len(t, found.Manifests, 3)
+
+	t.Run("not_recursive", func(t *testing.T) {
+		found, err := NewFileReader().Find(sticks)
+		require.NoError(t, err)
+		assert.Equal(t, split.Shares[1:2], found.MnemonicShares())
+		assert.Empty(t, found.Manifests)
+	})
+
+	t.Run("no_match", func(t *testing.T) {
+		_, err := reader.Find([]string{filepath.Join(media, "cdrom*")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no files match")
+	})
+
+	t.Run("conflicting_copies", func(t *testing.T) {
+		// flipping the same bits of two bytes keeps the check byte
+		data, err := split.Shares[0].ToShamir()
+		require.NoError(t, err)
+		data[0], data[1] = data[0]^0xff, data[1]^0xff
+		other, err := model.NewMnemonicShareFromShamir(data)
+		require.NoError(t, err)
+		other.Total = 3
+		conflict := filepath.Join(t.TempDir(), "other.txt")
+		_, err = WriteShares([]model.MnemonicShare{other}, conflict)
+		require.NoError(t, err)
+
+		_, err = reader.Find([]string{sticks[0], conflict})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "conflicting copies of share 0x01")
+	})
+}
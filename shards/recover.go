@@ -0,0 +1,36 @@
+package shards
+
+import (
+	"github.com/victorges/recovery-shards/command"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+// RecoverOptions are the options of a Recoverer.
+type RecoverOptions struct {
+	// Manifest is the manifest of the share set, if known. The shares are
+	// then checked to belong to the set, and the recovered secret to be the
+	// one it was created for.
+	Manifest *model.Manifest
+}
+
+// Recoverer combines shares to recover the secret they were split from.
+type Recoverer struct {
+	opts RecoverOptions
+}
+
+// NewRecoverer creates a Recoverer.
+func NewRecoverer(opts RecoverOptions) *Recoverer {
+	return &Recoverer{opts: opts}
+}
+
+// Recover combines the shares and returns the secret, which should be wiped
+// with its Wipe method once it is no longer needed. Without a manifest, a set
+// of shares that is too small or from different splits is not detected, and
+// results in a wrong secret or an error.
+func (r *Recoverer) Recover(shares []model.MnemonicShare) (seed.Secret, error) {
+	if r.opts.Manifest != nil {
+		return command.RecoverWithManifest(r.opts.Manifest, shares)
+	}
+	return command.RecoverSecret(shares)
+}
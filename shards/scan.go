@@ -0,0 +1,263 @@
+package shards
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/wordcode"
+)
+
+// ErrNoShares is returned by a Decoder for an input in which no share was
+// found, like a note or a photo next to the share files.
+var ErrNoShares = errors.New("no shares found")
+
+var (
+	// numberedHeader matches the header of a numbered share anywhere in a
+	// line, like "Share 3 of 5 (0x03c4):"
+	numberedHeader = regexp.MustCompile(`(?i)share\s+(\d+)\s+of\s+(\d+)\s*\(\s*((?:0x)?[0-9a-f]+)\s*\)\s*:?`)
+	// legacyIdentifier matches the identifier that starts a legacy share,
+	// like "0x5954:", "0x5954" or "5954:"
+	legacyIdentifier = regexp.MustCompile(`^(0x[0-9a-f]+):?$|^([0-9a-f]+):$`)
+	// bareIdentifier matches an identifier without any prefix or suffix, as
+	// in "5954 ankle salad ...", which is only read as such when the rest of
+	// the line is a full phrase
+	bareIdentifier = regexp.MustCompile(`^(?:0x)?[0-9a-f]+$`)
+	// listMarker matches the marker of an item of a list, like "1.", "(2)",
+	// "3:" or "-"
+	listMarker = regexp.MustCompile(`^(\(?\d+[.):]|[-*+>•])$`)
+)
+
+// lineTokens splits a line of text into lowercase tokens on whitespace, commas
+// and semicolons, without the markdown emphasis around them, a trailing
+// period, and the markers of list items.
+func lineTokens(line string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';'
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, "*_`\"'")
+		if field == "" || listMarker.MatchString(field) {
+			continue
+		}
+		if field = strings.TrimSuffix(field, "."); field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// textTokens returns the tokens of all the lines of text.
+func textTokens(text string) []string {
+	var tokens []string
+	for _, line := range strings.Split(text, "\n") {
+		tokens = append(tokens, lineTokens(line)...)
+	}
+	return tokens
+}
+
+// shareText is the text of a share found in the input: its identifier, the
+// number and total of a numbered share, and its word tokens along with the
+// line each one was found on.
+type shareText struct {
+	line          int
+	number, total string
+	identifier    string
+	tokens        []string
+	tokenLines    []int
+}
+
+func (t *shareText) add(tokens []string, line int) {
+	t.tokens = append(t.tokens, tokens...)
+	for range tokens {
+		t.tokenLines = append(t.tokenLines, line)
+	}
+}
+
+// complete returns whether the share has a valid number of words so far.
+func (t *shareText) complete() bool {
+	return model.IsWordCountValid(len(t.tokens))
+}
+
+// decodeText pulls the shares out of text that may hold anything else, like
+// the output of the tool, markdown notes or a README. A share starts with its
+// header or identifier, and its words may follow on the same line or on the
+// next ones, like in a numbered list, separated by spaces or commas. Lines
+// that are not part of a share are skipped.
+func (d *Decoder) decodeText(content string) ([]model.MnemonicShare, error) {
+	lines := strings.Split(content, "\n")
+	shares := make([]model.MnemonicShare, 0)
+	label := ""
+	// orphan is the first line holding a full phrase without an identifier
+	orphan := 0
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		text, ok := d.shareStart(line, i+1)
+		if !ok {
+			if value, ok := strings.CutPrefix(line, model.LabelComment); ok {
+				// comments are ignored, except for the label of the next share
+				label = strings.TrimSpace(value)
+			} else if tokens := lineTokens(line); orphan == 0 && !strings.HasPrefix(line, "#") &&
+				model.IsWordCountValid(len(tokens)) && d.allWords(tokens) {
+				orphan = i + 1
+			}
+			continue
+		}
+
+		i = d.continueShare(text, lines, i)
+		if len(text.tokens) == 0 {
+			// a header alone, like in "Share 1 of 3 (0x01c3)" as a title
+			continue
+		}
+		share, err := d.decodeShare(text)
+		if err != nil {
+			return nil, err
+		}
+		share.Label, label = label, ""
+		shares = append(shares, share)
+	}
+
+	if len(shares) == 0 {
+		if orphan > 0 {
+			return nil, &ParseError{Line: orphan, Err: fmt.Errorf("share file must contain identifiers")}
+		}
+		return nil, ErrNoShares
+	}
+	return shares, nil
+}
+
+// shareStart returns the start of a share if the line has one: a numbered
+// share header, or a legacy share identifier at the start of the line, along
+// with the words that follow it. A header within a sentence, like in "share
+// 0x01c3 is in the bank", is not the start of a share.
+func (d *Decoder) shareStart(line string, number int) (*shareText, bool) {
+	match := numberedHeader.FindStringSubmatchIndex(line)
+	if strings.HasPrefix(line, "#") && match == nil {
+		return nil, false
+	}
+
+	text := &shareText{line: number}
+	var tokens []string
+	if match != nil {
+		text.number = line[match[2]:match[3]]
+		text.total = line[match[4]:match[5]]
+		text.identifier = strings.ToLower(line[match[6]:match[7]])
+		tokens = lineTokens(line[match[1]:])
+	} else {
+		tokens = lineTokens(line)
+		if len(tokens) == 0 {
+			return nil, false
+		}
+		if id := legacyIdentifier.FindStringSubmatch(tokens[0]); id != nil {
+			text.identifier = id[1] + id[2]
+			tokens = tokens[1:]
+		} else if bareIdentifier.MatchString(tokens[0]) && model.IsWordCountValid(len(tokens)-1) && d.allWords(tokens[1:]) {
+			text.identifier = tokens[0]
+			tokens = tokens[1:]
+		} else {
+			return nil, false
+		}
+	}
+
+	if len(tokens) > 0 && !d.mostlyWords(tokens) {
+		return nil, false
+	}
+	text.add(tokens, number)
+	return text, true
+}
+
+// continueShare adds the words of the lines following the start of a share at
+// lines[i] to it, and returns the index of its last line. The share ends at
+// the next share or comment, or at the first line that is not made of words.
+// A numbered or mostly valid line with an invalid word is still part of a
+// share that is not complete, so that the word is reported.
+func (d *Decoder) continueShare(text *shareText, lines []string, i int) int {
+	last := i
+	for j := i + 1; j < len(lines); j++ {
+		line := strings.TrimSpace(lines[j])
+		tokens := lineTokens(line)
+		if len(tokens) == 0 {
+			if text.complete() && line == "" {
+				break
+			}
+			continue
+		}
+		if _, ok := d.shareStart(line, j+1); ok || strings.HasPrefix(line, "#") {
+			break
+		}
+		// a numbered line is an item of the list of words even with a typo
+		numbered := listMarker.MatchString(strings.Fields(line)[0])
+		if !d.allWords(tokens) && (text.complete() || !(numbered || d.mostlyWords(tokens))) {
+			break
+		}
+		text.add(tokens, j+1)
+		last = j
+	}
+	return last
+}
+
+// decodeShare creates the share of the text, or returns a ParseError with the
+// line of the word or header that is not valid.
+func (d *Decoder) decodeShare(text *shareText) (model.MnemonicShare, error) {
+	kind := "invalid mnemonic line"
+	if text.number != "" {
+		kind = "invalid share line"
+	}
+
+	mnemonic, err := wordcode.ExpandPhrase(strings.Join(text.tokens, " "), d.IndexBase)
+	if err != nil {
+		line := text.line
+		var invalid *model.ErrInvalidWord
+		if errors.As(err, &invalid) && invalid.Position > 0 {
+			line = text.tokenLines[invalid.Position-1]
+		}
+		return model.MnemonicShare{}, &ParseError{Line: line, Err: fmt.Errorf("%s: %w", kind, err)}
+	}
+
+	parse, create := model.ParseMnemonicShare, model.NewMnemonicShare
+	if d.SkipChecksums {
+		parse, create = model.ParseUncheckedShare, model.NewUncheckedShare
+	}
+	var share model.MnemonicShare
+	if text.number != "" {
+		share, err = parse(fmt.Sprintf("Share %s of %s (%s): %s", text.number, text.total, text.identifier, mnemonic))
+	} else {
+		share, err = create(text.identifier, mnemonic)
+	}
+	if err != nil {
+		return model.MnemonicShare{}, &ParseError{Line: text.line, Err: fmt.Errorf("%s: %w", kind, err)}
+	}
+	return share, nil
+}
+
+// isWord returns whether the token is a word in any of the forms accepted by
+// wordcode.Expand.
+func (d *Decoder) isWord(token string) bool {
+	_, err := wordcode.Expand(token, d.IndexBase)
+	return err == nil
+}
+
+// allWords returns whether there are tokens and all of them are words.
+func (d *Decoder) allWords(tokens []string) bool {
+	for _, token := range tokens {
+		if !d.isWord(token) {
+			return false
+		}
+	}
+	return len(tokens) > 0
+}
+
+// mostlyWords returns whether at least half of the tokens are words.
+func (d *Decoder) mostlyWords(tokens []string) bool {
+	words := 0
+	for _, token := range tokens {
+		if d.isWord(token) {
+			words++
+		}
+	}
+	return words > 0 && 2*words >= len(tokens)
+}
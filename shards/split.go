@@ -0,0 +1,94 @@
+// Package shards splits secrets into mnemonic shares and recovers them, and
+// reads and writes the share files and manifests of the recovery-shards tool.
+// It is the library behind the command line tool, so that other programs can
+// split and recover secrets without running it.
+//
+// A Splitter splits a seed.Secret into shares and creates the manifest of the
+// share set, and a Recoverer combines shares back into the secret, checking
+// them against the manifest when one is given. Shares are written with an
+// Encoder and read with a Decoder, in the same text and JSON formats as the
+// command line tool.
+package shards
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/victorges/recovery-shards/command"
+	"github.com/victorges/recovery-shards/entropy"
+	"github.com/victorges/recovery-shards/model"
+	"github.com/victorges/recovery-shards/seed"
+	"github.com/victorges/recovery-shards/shamir"
+)
+
+// SplitOptions are the options of a Splitter.
+type SplitOptions struct {
+	// Total is the number of shares to create, and Threshold the number of
+	// them needed to recover the secret.
+	Total, Threshold int
+	// Rand is the source of all the randomness of the split. It is
+	// crypto/rand.Reader if nil.
+	Rand io.Reader
+	// ExtraEntropy is mixed into Rand if it is not empty, like random
+	// keystrokes typed by the user.
+	ExtraEntropy []byte
+	// Labels holds the custodian label of each share, in order, if any.
+	Labels []string
+}
+
+// Splitter splits secrets into shares.
+type Splitter struct {
+	opts SplitOptions
+}
+
+// Split is the result of splitting a secret.
+type Split struct {
+	Shares []model.MnemonicShare
+	// Manifest describes the share set, without any secret information.
+	Manifest *model.Manifest
+}
+
+// NewSplitter checks the options and creates a Splitter.
+func NewSplitter(opts SplitOptions) (*Splitter, error) {
+	if err := shamir.CheckParameters(opts.Total, opts.Threshold); err != nil {
+		return nil, err
+	}
+	if len(opts.Labels) > 0 && len(opts.Labels) != opts.Total {
+		return nil, fmt.Errorf("got %d labels for %d shares", len(opts.Labels), opts.Total)
+	}
+	return &Splitter{opts: opts}, nil
+}
+
+// Split splits the secret into shares, checks that every combination of
+// Threshold shares recovers it, and creates the manifest of the share set.
+func (s *Splitter) Split(secret seed.Secret) (*Split, error) {
+	random := s.opts.Rand
+	if random == nil {
+		random = rand.Reader
+	}
+	if len(s.opts.ExtraEntropy) > 0 {
+		mixed, err := entropy.MixedReader(random, s.opts.ExtraEntropy)
+		if err != nil {
+			return nil, err
+		}
+		random = mixed
+	}
+
+	shares, err := command.SplitSecretWithRand(secret, s.opts.Total, s.opts.Threshold, random)
+	if err != nil {
+		return nil, err
+	}
+	if err := command.VerifySecretShares(secret, shares, s.opts.Threshold); err != nil {
+		return nil, fmt.Errorf("failed to verify shares: %w", err)
+	}
+	for i, label := range s.opts.Labels {
+		shares[i].Label = label
+	}
+
+	manifest, err := command.NewManifest(secret, shares, s.opts.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Split{Shares: shares, Manifest: manifest}, nil
+}
@@ -0,0 +1,79 @@
+package shards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/entropy"
+	"github.com/victorges/recovery-shards/seed"
+)
+
+const testMnemonic = "goose apple ecology ill reduce poem wish olive guitar health run chimney limb village nice dismiss razor meat property try talent toward clever cherry"
+
+func TestSplitAndRecover(t *testing.T) {
+	secret, err := seed.Parse(seed.BIP39, testMnemonic)
+	require.NoError(t, err)
+
+	splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2, Labels: []string{"Alice", "Bob", "Carol"}})
+	require.NoError(t, err)
+	split, err := splitter.Split(secret)
+	require.NoError(t, err)
+	require.Len(t, split.Shares, 3)
+	assert.Equal(t, "Bob", split.Shares[1].Label)
+	assert.Equal(t, 2, split.Manifest.Threshold)
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, split.Manifest.Labels)
+
+	recovered, err := NewRecoverer(RecoverOptions{Manifest: split.Manifest}).Recover(split.Shares[1:])
+	require.NoError(t, err)
+	assert.Equal(t, secret, recovered)
+
+	t.Run("deterministic", func(t *testing.T) {
+		split := func() []string {
+			splitter, err := NewSplitter(SplitOptions{Total: 3, Threshold: 2, Rand: entropy.NewDRBG([]byte("seed")), ExtraEntropy: []byte("keys")})
+			require.NoError(t, err)
+			result, err := splitter.Split(secret)
+			require.NoError(t, err)
+			mnemonics := make([]string, len(result.Shares))
+			for i, share := range result.Shares {
+				mnemonics[i] = share.Mnemonic
+			}
+			return mnemonics
+		}
+		assert.Equal(t, split(), split())
+	})
+
+	t.Run("other_set", func(t *testing.T) {
+		other, err := splitter.Split(secret)
+		require.NoError(t, err)
+		_, err = NewRecoverer(RecoverOptions{Manifest: split.Manifest}).Recover(other.Shares[:2])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not part of share set "+split.Manifest.SetID)
+	})
+
+	t.Run("one_share", func(t *testing.T) {
+		_, err := NewRecoverer(RecoverOptions{}).Recover(split.Shares[:1])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 2 shares are required")
+	})
+}
+
+func TestNewSplitter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   SplitOptions
+		errMsg string
+	}{
+		{"threshold_above_total", SplitOptions{Total: 2, Threshold: 3}, "parts cannot be less than threshold"},
+		{"threshold_too_low", SplitOptions{Total: 3, Threshold: 1}, "threshold must be at least 2"},
+		{"too_many_shares", SplitOptions{Total: 256, Threshold: 2}, "parts cannot exceed 255"},
+		{"labels", SplitOptions{Total: 3, Threshold: 2, Labels: []string{"Alice"}}, "got 1 labels for 3 shares"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSplitter(tc.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
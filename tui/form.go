@@ -0,0 +1,383 @@
+// Package tui implements a full-screen terminal form to enter mnemonic phrases
+// and shares, with a grid of words, autocomplete from the BIP39 wordlist,
+// arrow-key navigation, masked input and validation as the user types.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/wordcode"
+)
+
+// ErrAborted is returned when the user aborts the input with Ctrl-C.
+var ErrAborted = errors.New("input aborted")
+
+const (
+	// columns is the number of words in each row of the grid.
+	columns = 4
+	// cellWidth is the width of the text of each cell of the grid.
+	cellWidth = 10
+	// maxSuggestions is the number of autocomplete suggestions shown while
+	// the words are revealed.
+	maxSuggestions = 6
+	// mask is shown instead of the letters of masked words.
+	mask = "•"
+)
+
+// ANSI escape sequences used to draw the form.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	reverse     = "\x1b[7m"
+	red         = "\x1b[31m"
+	green       = "\x1b[32m"
+	dim         = "\x1b[2m"
+	reset       = "\x1b[0m"
+)
+
+// Form is the state of a form to enter a mnemonic phrase and, optionally, the
+// identifier of the share it belongs to.
+type Form struct {
+	// Title is shown on the first line of the form.
+	Title string
+	// Header holds extra lines shown under the title, like the progress of a
+	// multi-share entry.
+	Header []string
+	// Identifier is whether the form has a field for the share identifier.
+	Identifier bool
+	// WordCounts lists the numbers of words the user can choose from with
+	// Ctrl-E.
+	WordCounts []int
+	// IndexBase is the first decimal word index accepted, 0 or 1.
+	IndexBase int
+	// Validate checks a complete identifier and the words of the phrase, like
+	// the checksum of a share. It is optional.
+	Validate func(identifier string, words []string) error
+
+	identifier string
+	cells      []string
+	// focus is the index of the focused cell, or -1 for the identifier
+	focus  int
+	reveal bool
+}
+
+// NewForm creates a form for a phrase of the given number of words, which must
+// be one of wordCounts.
+func NewForm(title string, identifier bool, words int, wordCounts []int) *Form {
+	focus := 0
+	if identifier {
+		focus = -1
+	}
+	return &Form{
+		Title:      title,
+		Identifier: identifier,
+		WordCounts: wordCounts,
+		IndexBase:  1,
+		cells:      make([]string, words),
+		focus:      focus,
+	}
+}
+
+// Result returns the identifier and the words entered in the form. Once they
+// are expanded, the words are the entries of the wordlist, so the phrase is not
+// held in any string typed by the user.
+func (f *Form) Result() (identifier string, words []string) {
+	return strings.TrimSpace(f.identifier), slices.Clone(f.cells)
+}
+
+// Complete returns whether every word of the form is a valid word, and the
+// identifier is a valid hex string if the form has one.
+func (f *Form) Complete() bool {
+	if f.Identifier && f.identifierError() != nil {
+		return false
+	}
+	for _, cell := range f.cells {
+		if !isWord(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns why the form cannot be submitted yet, or nil if it can.
+func (f *Form) Err() error {
+	if f.Identifier {
+		if err := f.identifierError(); err != nil {
+			return err
+		}
+	}
+	filled := 0
+	for i, cell := range f.cells {
+		if cell != "" && !isWord(cell) && i != f.focus {
+			// the word is left out, as it may be a typo of a secret word
+			return fmt.Errorf("word %d is not in the wordlist", i+1)
+		}
+		if isWord(cell) {
+			filled++
+		}
+	}
+	if filled < len(f.cells) {
+		return fmt.Errorf("%d of %d words entered", filled, len(f.cells))
+	}
+	if f.Validate != nil {
+		return f.Validate(f.Result())
+	}
+	return nil
+}
+
+// HandleKey updates the form for a key pressed by the user. It returns true
+// once the form is submitted, or ErrAborted if the user aborts it.
+func (f *Form) HandleKey(key Key) (bool, error) {
+	switch key.Code {
+	case KeyCtrlC, KeyEscape:
+		return false, ErrAborted
+	case KeyCtrlR:
+		f.reveal = !f.reveal
+	case KeyCtrlE:
+		f.nextWordCount()
+	case KeyCtrlU:
+		f.setText("")
+	case KeyCtrlD:
+		f.expand()
+		return f.Err() == nil, nil
+	case KeyBackspace:
+		text := []rune(f.text())
+		if len(text) > 0 {
+			f.setText(string(text[:len(text)-1]))
+		} else if f.focus > f.first() {
+			f.move(f.focus - 1)
+		}
+	case KeyDelete:
+		f.setText("")
+	case KeyTab:
+		f.complete()
+	case KeyEnter:
+		f.expand()
+		if f.focus == len(f.cells)-1 || f.Complete() {
+			return f.Err() == nil, nil
+		}
+		f.move(f.focus + 1)
+	case KeyUp:
+		if f.focus >= columns {
+			f.move(f.focus - columns)
+		} else {
+			f.move(f.first())
+		}
+	case KeyDown:
+		if f.focus < 0 {
+			f.move(0)
+		} else if f.focus+columns < len(f.cells) {
+			f.move(f.focus + columns)
+		}
+	case KeyLeft:
+		if f.focus > f.first() {
+			f.move(f.focus - 1)
+		}
+	case KeyRight:
+		if f.focus < len(f.cells)-1 {
+			f.move(f.focus + 1)
+		}
+	case KeyHome:
+		f.move(f.first())
+	case KeyEnd:
+		f.move(len(f.cells) - 1)
+	case KeyRune:
+		if key.Rune == ' ' {
+			if f.text() != "" && f.focus < len(f.cells)-1 {
+				f.expand()
+				f.move(f.focus + 1)
+			}
+			return false, nil
+		}
+		f.setText(f.text() + strings.ToLower(string(key.Rune)))
+	}
+	return false, nil
+}
+
+// Render draws the whole form to w.
+func (f *Form) Render(w io.Writer) {
+	var lines []string
+	lines = append(lines, f.Title)
+	lines = append(lines, f.Header...)
+	lines = append(lines, "")
+
+	if f.Identifier {
+		field := f.identifier
+		if f.focus == -1 {
+			field = reverse + field + "_" + reset
+		}
+		status := green + "ok" + reset
+		if err := f.identifierError(); err != nil {
+			status = red + err.Error() + reset
+		}
+		lines = append(lines, fmt.Sprintf("Identifier: %s  %s", field, status), "")
+	}
+
+	for row := 0; row < len(f.cells); row += columns {
+		var line strings.Builder
+		for i := row; i < min(row+columns, len(f.cells)); i++ {
+			line.WriteString(f.renderCell(i))
+		}
+		lines = append(lines, line.String())
+	}
+	lines = append(lines, "")
+
+	// the suggestions would show the masked word once a few letters are typed
+	if suggestions := f.suggestions(); f.reveal && len(suggestions) > 0 {
+		lines = append(lines, "Suggestions: "+strings.Join(suggestions, " "))
+	} else {
+		lines = append(lines, "")
+	}
+	if err := f.Err(); err != nil {
+		lines = append(lines, red+err.Error()+reset)
+	} else {
+		lines = append(lines, green+"All words are valid, press Enter to continue."+reset)
+	}
+	lines = append(lines, "", dim+"Arrows: move  Tab: complete  Enter: next  Ctrl-U: clear  Ctrl-R: reveal  Ctrl-E: word count  Ctrl-C: abort"+reset)
+
+	fmt.Fprint(w, clearScreen+strings.Join(lines, "\r\n"))
+}
+
+// renderCell returns the text of a cell of the grid, with its number.
+func (f *Form) renderCell(i int) string {
+	text := f.cells[i]
+	shown := text
+	if !f.reveal {
+		shown = strings.Repeat(mask, len([]rune(text)))
+	}
+	if i == f.focus {
+		shown += "_"
+	}
+	if pad := cellWidth - len([]rune(shown)); pad > 0 {
+		shown += strings.Repeat(" ", pad)
+	}
+
+	switch {
+	case i == f.focus:
+		shown = reverse + shown + reset
+	case text != "" && !isWord(text):
+		shown = red + shown + reset
+	}
+	return fmt.Sprintf("%3d %s ", i+1, shown)
+}
+
+// suggestions returns the words that start with the text of the focused cell.
+func (f *Form) suggestions() []string {
+	prefix := f.text()
+	if f.focus < 0 || prefix == "" {
+		return nil
+	}
+	list := bip39.GetWordList()
+	var words []string
+	for i := sort.SearchStrings(list, prefix); i < len(list) && strings.HasPrefix(list[i], prefix); i++ {
+		if len(words) == maxSuggestions {
+			return append(words, "...")
+		}
+		words = append(words, list[i])
+	}
+	return words
+}
+
+// complete replaces the text of the focused cell by the longest common prefix
+// of its suggestions, which is the whole word if there is only one.
+func (f *Form) complete() {
+	prefix := f.text()
+	if f.focus < 0 || prefix == "" {
+		return
+	}
+	list := bip39.GetWordList()
+	start := sort.SearchStrings(list, prefix)
+	end := start
+	for end < len(list) && strings.HasPrefix(list[end], prefix) {
+		end++
+	}
+	if end == start {
+		return
+	}
+	common := list[start]
+	for _, word := range list[start+1 : end] {
+		for !strings.HasPrefix(word, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	f.setText(common)
+}
+
+// expand replaces the text of the focused cell by the word it stands for, if
+// it is a prefix, an index or a dot pattern of a word.
+func (f *Form) expand() {
+	if f.focus < 0 || f.text() == "" {
+		return
+	}
+	if word, err := wordcode.Expand(f.text(), f.IndexBase); err == nil {
+		f.setText(word)
+	}
+}
+
+// move expands the focused cell and moves the focus to cell i.
+func (f *Form) move(i int) {
+	f.expand()
+	f.focus = i
+}
+
+// nextWordCount changes the number of words to the next one of WordCounts,
+// keeping the words already entered.
+func (f *Form) nextWordCount() {
+	if len(f.WordCounts) == 0 {
+		return
+	}
+	next := f.WordCounts[0]
+	if i := slices.Index(f.WordCounts, len(f.cells)); i >= 0 {
+		next = f.WordCounts[(i+1)%len(f.WordCounts)]
+	}
+	cells := make([]string, next)
+	copy(cells, f.cells)
+	f.cells = cells
+	f.focus = min(f.focus, next-1)
+}
+
+func (f *Form) first() int {
+	if f.Identifier {
+		return -1
+	}
+	return 0
+}
+
+func (f *Form) text() string {
+	if f.focus < 0 {
+		return f.identifier
+	}
+	return f.cells[f.focus]
+}
+
+func (f *Form) setText(text string) {
+	if f.focus < 0 {
+		f.identifier = text
+	} else {
+		f.cells[f.focus] = text
+	}
+}
+
+// identifierError returns why the identifier is not valid, or nil.
+func (f *Form) identifierError() error {
+	id := strings.TrimPrefix(strings.TrimSpace(f.identifier), "0x")
+	switch {
+	case id == "":
+		return fmt.Errorf("identifier missing")
+	case len(id)%2 != 0 || strings.Trim(id, "0123456789abcdef") != "":
+		return fmt.Errorf("identifier must be hex, like 0x03c4")
+	case len(id) < 4:
+		return fmt.Errorf("identifier too short")
+	}
+	return nil
+}
+
+func isWord(text string) bool {
+	_, ok := bip39.GetWordIndex(text)
+	return ok && text != ""
+}
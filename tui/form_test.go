@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/victorges/recovery-shards/model"
+)
+
+const (
+	testIdentifier = "0xade1"
+	testMnemonic   = "drum wage genuine tourist slim hungry fragile lava shop apple large off cheap hover trial phrase bag cost sell person salt amount cute lottery"
+)
+
+func runForm(t *testing.T, f *Form, input string) (string, string, string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	identifier, words, err := Run(bufio.NewReader(strings.NewReader(input)), &out, f)
+	return identifier, strings.Join(words, " "), out.String(), err
+}
+
+func shareForm() *Form {
+	form := NewForm("Enter share 1 of 2", true, 24, []int{12, 24, 36})
+	form.Validate = func(identifier string, words []string) error {
+		_, err := model.NewMnemonicShare(identifier, strings.Join(words, " "))
+		return err
+	}
+	return form
+}
+
+func TestFormEntry(t *testing.T) {
+	// Type the identifier, then each word as a 4-letter prefix
+	var input strings.Builder
+	input.WriteString(testIdentifier + "\r")
+	for _, word := range strings.Fields(testMnemonic) {
+		input.WriteString(word[:min(4, len(word))] + " ")
+	}
+	input.WriteString("\r")
+
+	identifier, mnemonic, screen, err := runForm(t, shareForm(), input.String())
+	require.NoError(t, err)
+	assert.Equal(t, testIdentifier, identifier)
+	assert.Equal(t, testMnemonic, mnemonic)
+	assert.NotContains(t, screen, "Suggestions:")
+	assert.NotContains(t, screen, "lottery")
+}
+
+func TestFormEditing(t *testing.T) {
+	form := shareForm()
+	words := strings.Fields(testMnemonic)
+	words[16] = "zoo" // a wrong word that is caught by the checksum
+
+	input := testIdentifier + "\r" + strings.Join(words, " ") + "\r"
+	_, _, screen, err := runForm(t, form, input)
+	require.Error(t, err, "the form must not be submitted with a wrong word")
+	assert.Contains(t, screen, "invalid")
+
+	// Go back to word 17 with the arrows, fix it and submit
+	fix := "\x1b[A\x1b[D\x1b[D\x1b[D\x15bag\r"
+	require.Equal(t, 23, form.focus)
+	identifier, mnemonic, _, err := runForm(t, form, fix)
+	require.NoError(t, err)
+	assert.Equal(t, testIdentifier, identifier)
+	assert.Equal(t, testMnemonic, mnemonic)
+}
+
+func TestFormKeys(t *testing.T) {
+	t.Run("autocomplete", func(t *testing.T) {
+		form := NewForm("", false, 12, nil)
+		_, err := form.HandleKey(Key{Code: KeyRune, Rune: 'l'})
+		require.NoError(t, err)
+		_, err = form.HandleKey(Key{Code: KeyRune, Rune: 'o'})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"load", "loan", "lobster", "local", "lock", "logic", "..."}, form.suggestions())
+
+		_, err = form.HandleKey(Key{Code: KeyRune, Rune: 't'})
+		require.NoError(t, err)
+		_, err = form.HandleKey(Key{Code: KeyTab})
+		require.NoError(t, err)
+		assert.Equal(t, "lottery", form.cells[0])
+	})
+
+	t.Run("index_and_dots", func(t *testing.T) {
+		form := NewForm("", false, 12, nil)
+		form.IndexBase = 0
+		for _, key := range []Key{{Code: KeyRune, Rune: '3'}, {Code: KeyRight}, {Code: KeyRune, Rune: '●'}} {
+			_, err := form.HandleKey(key)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "about", form.cells[0])
+		assert.Equal(t, "●", form.cells[1])
+	})
+
+	t.Run("word_count", func(t *testing.T) {
+		form := NewForm("", false, 24, []int{12, 24, 36})
+		form.cells[0] = "zoo"
+		_, err := form.HandleKey(Key{Code: KeyCtrlE})
+		require.NoError(t, err)
+		assert.Len(t, form.cells, 36)
+		_, err = form.HandleKey(Key{Code: KeyCtrlE})
+		require.NoError(t, err)
+		assert.Len(t, form.cells, 12)
+		assert.Equal(t, "zoo", form.cells[0])
+	})
+
+	t.Run("reveal", func(t *testing.T) {
+		form := NewForm("", false, 12, nil)
+		form.cells[0], form.cells[1], form.cells[2] = "lottery", "zzz", "lott"
+		form.focus = 2
+		var out bytes.Buffer
+		form.Render(&out)
+		assert.NotContains(t, out.String(), "lottery")
+		assert.NotContains(t, out.String(), "zzz")
+		assert.NotContains(t, out.String(), "Suggestions:")
+		assert.Contains(t, out.String(), "word 2 is not in the wordlist")
+
+		_, err := form.HandleKey(Key{Code: KeyCtrlR})
+		require.NoError(t, err)
+		out.Reset()
+		form.Render(&out)
+		assert.Contains(t, out.String(), "lottery")
+		assert.Contains(t, out.String(), "zzz")
+		assert.Contains(t, out.String(), "Suggestions: lottery")
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		_, _, _, err := runForm(t, NewForm("", false, 12, nil), "aban\x03")
+		assert.ErrorIs(t, err, ErrAborted)
+	})
+}
+
+func TestProgress(t *testing.T) {
+	lines := Progress([]string{"0x01f5"}, 3)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "[########----------------] 1 of 3 shares entered", lines[0])
+	assert.Contains(t, lines[1], "share 1: 0x01f5")
+}
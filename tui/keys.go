@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"unicode/utf8"
+)
+
+// KeyCode identifies a key pressed in the terminal.
+type KeyCode int
+
+const (
+	// KeyRune is a printable character, stored in Key.Rune.
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	// KeyCtrlC aborts the input.
+	KeyCtrlC
+	// KeyCtrlD submits the input when it is complete.
+	KeyCtrlD
+	// KeyCtrlE changes the number of words.
+	KeyCtrlE
+	// KeyCtrlR toggles between masked and revealed words.
+	KeyCtrlR
+	// KeyCtrlU clears the focused field.
+	KeyCtrlU
+	// KeyUnknown is any other control character or escape sequence.
+	KeyUnknown
+)
+
+// Key is a key pressed in the terminal.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// controlKeys maps the control characters sent by terminals in raw mode to
+// their keys.
+var controlKeys = map[byte]KeyCode{
+	0x03: KeyCtrlC,
+	0x04: KeyCtrlD,
+	0x05: KeyCtrlE,
+	0x08: KeyBackspace,
+	0x09: KeyTab,
+	0x0a: KeyEnter,
+	0x0d: KeyEnter,
+	0x12: KeyCtrlR,
+	0x15: KeyCtrlU,
+	0x7f: KeyBackspace,
+}
+
+// escapeKeys maps the final byte of CSI and SS3 escape sequences to their keys.
+var escapeKeys = map[byte]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+}
+
+// tildeKeys maps the number of "ESC [ n ~" escape sequences to their keys.
+var tildeKeys = map[string]KeyCode{
+	"1": KeyHome,
+	"3": KeyDelete,
+	"4": KeyEnd,
+	"7": KeyHome,
+	"8": KeyEnd,
+}
+
+// ReadKey reads the next key from a terminal in raw mode.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+
+	if b == 0x1b {
+		// a lone escape is sent by itself, while escape sequences arrive
+		// in a single read
+		if r.Buffered() == 0 {
+			return Key{Code: KeyEscape}, nil
+		}
+		return readEscape(r)
+	}
+	if code, ok := controlKeys[b]; ok {
+		return Key{Code: code}, nil
+	}
+	if b < 0x20 {
+		return Key{Code: KeyUnknown}, nil
+	}
+	if b < utf8.RuneSelf {
+		return Key{Code: KeyRune, Rune: rune(b)}, nil
+	}
+
+	if err := r.UnreadByte(); err != nil {
+		return Key{}, err
+	}
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to read key: %w", err)
+	}
+	return Key{Code: KeyRune, Rune: c}, nil
+}
+
+// readEscape reads the rest of an escape sequence, after the escape byte.
+func readEscape(r *bufio.Reader) (Key, error) {
+	intro, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	if intro != '[' && intro != 'O' {
+		return Key{Code: KeyUnknown}, nil
+	}
+
+	params := ""
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		switch {
+		case b >= '0' && b <= '9' || b == ';':
+			params += string(b)
+		case b == '~':
+			if code, ok := tildeKeys[params]; ok {
+				return Key{Code: code}, nil
+			}
+			return Key{Code: KeyUnknown}, nil
+		default:
+			if code, ok := escapeKeys[b]; ok {
+				return Key{Code: code}, nil
+			}
+			return Key{Code: KeyUnknown}, nil
+		}
+	}
+}
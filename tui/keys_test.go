@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadKey(t *testing.T) {
+	input := "a\x1b[A\x1b[B\x1bOC\x1b[D\x1b[3~\x1b[1;5H\x7f\x08\r\t\x03\x12é\x1b"
+	expected := []Key{
+		{Code: KeyRune, Rune: 'a'},
+		{Code: KeyUp},
+		{Code: KeyDown},
+		{Code: KeyRight},
+		{Code: KeyLeft},
+		{Code: KeyDelete},
+		{Code: KeyHome},
+		{Code: KeyBackspace},
+		{Code: KeyBackspace},
+		{Code: KeyEnter},
+		{Code: KeyTab},
+		{Code: KeyCtrlC},
+		{Code: KeyCtrlR},
+		{Code: KeyRune, Rune: 'é'},
+		{Code: KeyEscape},
+	}
+
+	r := bufio.NewReader(strings.NewReader(input))
+	for i, want := range expected {
+		key, err := ReadKey(r)
+		require.NoError(t, err, "key %d", i)
+		assert.Equal(t, want, key, "key %d", i)
+	}
+	_, err := ReadKey(r)
+	assert.ErrorIs(t, err, io.EOF)
+}
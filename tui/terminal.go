@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	// clearScrollback erases the scrollback, in terminals that support it
+	clearScrollback = "\x1b[3J"
+)
+
+// Terminal is an interactive terminal in raw mode, showing the forms on the
+// alternate screen so that no words are left in its scrollback once it is
+// closed.
+type Terminal struct {
+	in    *bufio.Reader
+	out   io.Writer
+	fd    int
+	state *term.State
+}
+
+// Open puts the terminal of in in raw mode and switches out to the alternate
+// screen. It fails if in is not a terminal.
+func Open(in *os.File, out io.Writer) (*Terminal, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("the terminal UI requires an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	fmt.Fprint(out, enterAltScreen)
+	return &Terminal{in: bufio.NewReader(in), out: out, fd: fd, state: state}, nil
+}
+
+// Close clears the screen and restores the terminal to its original state.
+func (t *Terminal) Close() error {
+	fmt.Fprint(t.out, clearScreen+exitAltScreen)
+	return term.Restore(t.fd, t.state)
+}
+
+// Run shows the form until it is submitted, and returns the identifier and
+// words entered.
+func (t *Terminal) Run(f *Form) (identifier string, words []string, err error) {
+	return Run(t.in, t.out, f)
+}
+
+// Run shows the form on out and handles the keys read from in until the form
+// is submitted, and returns the identifier and words entered. The terminal
+// must already be in raw mode.
+func Run(in *bufio.Reader, out io.Writer, f *Form) (identifier string, words []string, err error) {
+	for {
+		f.Render(out)
+		key, err := ReadKey(in)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		done, err := f.HandleKey(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if done {
+			identifier, words = f.Result()
+			return identifier, words, nil
+		}
+	}
+}
+
+// Progress returns the header lines of a multi-share entry, with a progress bar
+// and the identifiers of the shares already entered.
+func Progress(entered []string, total int) []string {
+	const width = 24
+	done := width * len(entered) / max(total, 1)
+	bar := fmt.Sprintf("[%s%s] %d of %d shares entered",
+		strings.Repeat("#", done), strings.Repeat("-", width-done), len(entered), total)
+
+	lines := []string{bar}
+	for i, id := range entered {
+		lines = append(lines, fmt.Sprintf("  %s share %d: %s", green+"✓"+reset, i+1, id))
+	}
+	return lines
+}
+
+// Show displays a title and the text written by render on the alternate
+// screen of the terminal of in until the user presses a key, and then clears
+// the screen and the scrollback. The text is written straight to out, so it
+// is not held in a string.
+func Show(in *os.File, out io.Writer, title string, render func(w io.Writer) error) error {
+	t, err := Open(in, out)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, clearScreen+title+"\r\n\r\n")
+	renderErr := render(out)
+	fmt.Fprint(out, "\r\n\r\nPress any key to clear the screen.")
+	var readErr error
+	if renderErr == nil {
+		_, readErr = ReadKey(t.in)
+	}
+	if err := t.Close(); err != nil {
+		return err
+	}
+	fmt.Fprint(out, clearScreen+clearScrollback)
+	if renderErr != nil {
+		return renderErr
+	}
+	if readErr != nil {
+		return fmt.Errorf("failed to read input: %w", readErr)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Purpose is a BIP43 purpose, which defines the derivation path and the
+// address type of a wallet account.
+type Purpose uint32
+
+const (
+	// BIP44 accounts use legacy P2PKH addresses.
+	BIP44 Purpose = 44
+	// BIP49 accounts use P2WPKH nested in P2SH addresses.
+	BIP49 Purpose = 49
+	// BIP84 accounts use native SegWit P2WPKH addresses.
+	BIP84 Purpose = 84
+	// BIP86 accounts use single-key Taproot P2TR addresses.
+	BIP86 Purpose = 86
+)
+
+// ParsePurpose parses a purpose name like "bip84" or "84".
+func ParsePurpose(name string) (Purpose, error) {
+	name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "bip")
+	for _, p := range []Purpose{BIP44, BIP49, BIP84, BIP86} {
+		if name == fmt.Sprint(uint32(p)) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown derivation path: %s (expected bip44, bip49, bip84 or bip86)", name)
+}
+
+// String returns the name of the purpose, like "bip84".
+func (p Purpose) String() string {
+	return fmt.Sprintf("bip%d", uint32(p))
+}
+
+// Account holds the public information of a wallet account, which can be used
+// to identify the wallet without exposing any private key.
+type Account struct {
+	Purpose Purpose
+	// Path is the derivation path of the account key
+	Path []uint32
+	// PublicKey is the account extended public key, serialized with the
+	// SLIP-132 version of the purpose (xpub, ypub or zpub on mainnet)
+	PublicKey string
+	// ReceiveAddress is the address of the first receive key, at path
+	// <account>/0/0
+	ReceiveAddress string
+}
+
+// DeriveAccount derives the given account of a master extended private key.
+func DeriveAccount(master *ExtendedKey, purpose Purpose, account uint32) (*Account, error) {
+	net := master.Network()
+	if net == nil || !master.IsPrivate() {
+		return nil, fmt.Errorf("account derivation requires an extended private key")
+	}
+	if master.Depth != 0 {
+		return nil, fmt.Errorf("extended key is not a master key (depth %d)", master.Depth)
+	}
+
+	path := []uint32{
+		uint32(purpose) + HardenedOffset,
+		net.CoinType + HardenedOffset,
+		account + HardenedOffset,
+	}
+	accountKey, err := master.Derive(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive account key: %w", err)
+	}
+
+	xpub := accountKey.Neuter()
+	receiveKey, err := xpub.Derive([]uint32{0, 0})
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive receive key: %w", err)
+	}
+	address, err := purpose.Address(receiveKey.PublicKey(), net)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := *xpub
+	versioned.Version = purpose.PublicVersion(net)
+	return &Account{
+		Purpose:        purpose,
+		Path:           path,
+		PublicKey:      versioned.String(),
+		ReceiveAddress: address,
+	}, nil
+}
+
+// PublicVersion returns the SLIP-132 extended public key version used for
+// accounts of the purpose.
+func (p Purpose) PublicVersion(net *Network) uint32 {
+	switch p {
+	case BIP49:
+		return net.NestedSegWitPublicVersion
+	case BIP84:
+		return net.SegWitPublicVersion
+	default:
+		return net.PublicVersion
+	}
+}
+
+// Address returns the address of the compressed public key for the address
+// type of the purpose.
+func (p Purpose) Address(pubKey []byte, net *Network) (string, error) {
+	switch p {
+	case BIP44:
+		return P2PKHAddress(pubKey, net), nil
+	case BIP49:
+		redeemScript := append([]byte{0x00, 0x14}, Hash160(pubKey)...)
+		return Base58CheckEncode(append([]byte{net.ScriptHashVersion}, Hash160(redeemScript)...)), nil
+	case BIP84:
+		return SegWitAddress(net.Bech32HRP, 0, Hash160(pubKey))
+	case BIP86:
+		outputKey, err := taprootOutputKey(pubKey)
+		if err != nil {
+			return "", err
+		}
+		return SegWitAddress(net.Bech32HRP, 1, outputKey)
+	default:
+		return "", fmt.Errorf("unknown purpose: %d", uint32(p))
+	}
+}
+
+// taprootOutputKey returns the x-only BIP341 output key for a key path only
+// spend with the given internal key, as specified by BIP86.
+func taprootOutputKey(pubKey []byte) ([]byte, error) {
+	internal, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	// Taproot internal keys are x-only, so the point with even Y is used
+	xOnly := internal.SerializeCompressed()[1:]
+	internal, err = secp256k1.ParsePubKey(append([]byte{0x02}, xOnly...))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(taggedHash("TapTweak", xOnly)); overflow {
+		return nil, fmt.Errorf("invalid taproot tweak")
+	}
+
+	var point, tweakPoint, output secp256k1.JacobianPoint
+	internal.AsJacobian(&point)
+	secp256k1.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	secp256k1.AddNonConst(&point, &tweakPoint, &output)
+	output.ToAffine()
+	outputKey := output.X.Bytes()
+	return outputKey[:], nil
+}
+
+// taggedHash returns the BIP340 tagged hash of msg.
+func taggedHash(tag string, msg []byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(msg)
+	return h.Sum(nil)
+}
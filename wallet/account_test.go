@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestDerive(t *testing.T) {
+	// BIP32 test vector 1
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+	master, err := NewMasterKey(seed, MainNet)
+	require.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi", master.String())
+
+	fp := master.Fingerprint()
+	assert.Equal(t, "3442193e", hex.EncodeToString(fp[:]))
+
+	path, err := ParsePath("m/0h/1")
+	require.NoError(t, err)
+	assert.Equal(t, "m/0'/1", FormatPath(path))
+
+	child, err := master.Derive(path)
+	require.NoError(t, err)
+	assert.Equal(t, "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs", child.String())
+	assert.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", child.Neuter().String())
+
+	// Public derivation of a non-hardened child matches private derivation
+	hardened, err := master.Child(HardenedOffset)
+	require.NoError(t, err)
+	pubChild, err := hardened.Neuter().Child(1)
+	require.NoError(t, err)
+	assert.Equal(t, child.Neuter().String(), pubChild.String())
+
+	_, err = master.Neuter().Child(HardenedOffset)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot derive hardened child from public key")
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{"", "84'/0'/0'", "m/x", "m/2147483648", "m/1''"} {
+		_, err := ParsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestDeriveAccount(t *testing.T) {
+	// Test vectors from BIP44, BIP49, BIP84 and BIP86, which all use the same
+	// mnemonic
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	master, err := NewMasterKey(bip39.NewSeed(mnemonic, ""), MainNet)
+	require.NoError(t, err)
+
+	fp := master.Fingerprint()
+	assert.Equal(t, "73c5da0a", hex.EncodeToString(fp[:]))
+
+	testCases := []struct {
+		purpose Purpose
+		path    string
+		xpub    string
+		address string
+	}{
+		{
+			purpose: BIP44,
+			path:    "m/44'/0'/0'",
+			xpub:    "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj",
+			address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+		},
+		{
+			purpose: BIP49,
+			path:    "m/49'/0'/0'",
+			xpub:    "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP",
+			address: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf",
+		},
+		{
+			purpose: BIP84,
+			path:    "m/84'/0'/0'",
+			xpub:    "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs",
+			address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		},
+		{
+			purpose: BIP86,
+			path:    "m/86'/0'/0'",
+			xpub:    "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ",
+			address: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.purpose.String(), func(t *testing.T) {
+			purpose, err := ParsePurpose(tc.purpose.String())
+			require.NoError(t, err)
+			assert.Equal(t, tc.purpose, purpose)
+
+			account, err := DeriveAccount(master, purpose, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.path, FormatPath(account.Path))
+			assert.Equal(t, tc.xpub, account.PublicKey)
+			assert.Equal(t, tc.address, account.ReceiveAddress)
+		})
+	}
+
+	t.Run("not_master", func(t *testing.T) {
+		child, err := master.Child(HardenedOffset)
+		require.NoError(t, err)
+		_, err = DeriveAccount(child, BIP84, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a master key")
+	})
+}
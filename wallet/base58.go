@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+// Base58Encode encodes data using the Bitcoin base58 alphabet. Leading zero
+// bytes are encoded as leading '1' characters.
+func Base58Encode(data []byte) string {
+	num := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+
+	encoded := make([]byte, 0, len(data)*138/100+1)
+	for num.Sign() > 0 {
+		num.DivMod(num, bigRadix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+// Base58Decode decodes a string encoded with Base58Encode.
+func Base58Decode(s string) ([]byte, error) {
+	num := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		digit := bytes.IndexByte([]byte(base58Alphabet), s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at position %d", s[i], i)
+		}
+		num.Mul(num, bigRadix)
+		num.Add(num, big.NewInt(int64(digit)))
+	}
+
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), num.Bytes()...), nil
+}
+
+// Base58CheckEncode appends the 4-byte double SHA-256 checksum to data and
+// encodes the result in base58.
+func Base58CheckEncode(data []byte) string {
+	return Base58Encode(append(bytes.Clone(data), checksum(data)...))
+}
+
+// Base58CheckDecode decodes a Base58Check string, validating and removing its
+// 4-byte checksum.
+func Base58CheckDecode(s string) ([]byte, error) {
+	decoded, err := Base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, fmt.Errorf("base58check string too short")
+	}
+
+	data, sum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	if !bytes.Equal(checksum(data), sum) {
+		return nil, fmt.Errorf("invalid base58check checksum")
+	}
+	return data, nil
+}
+
+// checksum returns the first 4 bytes of the double SHA-256 of data.
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	// bech32Const and bech32mConst are the checksum constants of BIP173 and
+	// BIP350, used for witness version 0 and versions 1+ respectively.
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// SegWitAddress returns the native SegWit address of a witness program, using
+// bech32 for version 0 and bech32m for later versions.
+func SegWitAddress(hrp string, version byte, program []byte) (string, error) {
+	if version > 16 {
+		return "", fmt.Errorf("invalid witness version: %d", version)
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return "", fmt.Errorf("invalid witness program length: %d", len(program))
+	}
+
+	data, err := convertBits(program, 8, 5)
+	if err != nil {
+		return "", err
+	}
+	data = append([]byte{version}, data...)
+
+	constant := uint32(bech32Const)
+	if version > 0 {
+		constant = bech32mConst
+	}
+	return bech32Encode(hrp, data, constant), nil
+}
+
+// bech32Encode encodes 5-bit data with the given human-readable part and
+// checksum constant.
+func bech32Encode(hrp string, data []byte, constant uint32) string {
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, make([]byte, 6)...)
+	polymod := bech32Polymod(values) ^ constant
+
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, d := range data {
+		b.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[(polymod>>(5*(5-i)))&31])
+	}
+	return b.String()
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups data from groups of fromBits bits to groups of toBits
+// bits, padding the last group with zeros.
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, error) {
+	var acc, bits uint
+	maxValue := uint(1)<<toBits - 1
+	result := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, b := range data {
+		if uint(b)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data value: %d", b)
+		}
+		acc = acc<<fromBits | uint(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxValue))
+		}
+	}
+	if bits > 0 {
+		result = append(result, byte(acc<<(toBits-bits)&maxValue))
+	}
+	return result, nil
+}
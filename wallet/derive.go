@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// HardenedOffset is the index of the first hardened child key.
+const HardenedOffset = 0x80000000
+
+// NewMasterKey derives the BIP32 master extended private key from a seed, such
+// as the one derived from a BIP39 mnemonic.
+func NewMasterKey(seed []byte, net *Network) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	defer clear(sum)
+
+	if err := validatePrivateKey(sum[:32]); err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+
+	key := &ExtendedKey{Version: net.PrivateVersion}
+	copy(key.Key[1:], sum[:32])
+	copy(key.ChainCode[:], sum[32:])
+	return key, nil
+}
+
+// Child derives the child key with the given index. Hardened children (index
+// at or above HardenedOffset) can only be derived from private keys.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.Depth == 0xff {
+		return nil, fmt.Errorf("cannot derive child of key at maximum depth")
+	}
+	hardened := index >= HardenedOffset
+	if hardened && !k.IsPrivate() {
+		return nil, fmt.Errorf("cannot derive hardened child from public key")
+	}
+
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(data, k.Key[:]...)
+	} else {
+		data = append(data, k.PublicKey()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	defer clear(data)
+
+	mac := hmac.New(sha512.New, k.ChainCode[:])
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	defer clear(sum)
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	defer tweak.Zero()
+
+	child := &ExtendedKey{
+		Version:           k.Version,
+		Depth:             k.Depth + 1,
+		ParentFingerprint: k.Fingerprint(),
+		ChildNumber:       index,
+	}
+	copy(child.ChainCode[:], sum[32:])
+
+	if k.IsPrivate() {
+		var priv secp256k1.ModNScalar
+		priv.SetByteSlice(k.Key[1:])
+		priv.Add(&tweak)
+		if priv.IsZero() {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		keyBytes := priv.Bytes()
+		copy(child.Key[1:], keyBytes[:])
+		priv.Zero()
+		clear(keyBytes[:])
+		return child, nil
+	}
+
+	parent, err := secp256k1.ParsePubKey(k.Key[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	var point, tweakPoint, result secp256k1.JacobianPoint
+	parent.AsJacobian(&point)
+	secp256k1.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	secp256k1.AddNonConst(&point, &tweakPoint, &result)
+	if (result.X.IsZero() && result.Y.IsZero()) || result.Z.IsZero() {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	result.ToAffine()
+	copy(child.Key[:], secp256k1.NewPublicKey(&result.X, &result.Y).SerializeCompressed())
+	return child, nil
+}
+
+// Derive derives the descendant key at the given path, relative to the key.
+func (k *ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// ParsePath parses a derivation path like "m/84'/0'/0'". Hardened indexes may
+// be marked with either ' or h.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with m: %s", path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		offset := uint32(0)
+		if trimmed := strings.TrimRight(part, "'hH"); len(trimmed) == len(part)-1 {
+			part, offset = trimmed, HardenedOffset
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || index >= HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path index: %s", part)
+		}
+		indexes = append(indexes, uint32(index)+offset)
+	}
+	return indexes, nil
+}
+
+// FormatPath formats a derivation path, marking hardened indexes with '.
+func FormatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		if index >= HardenedOffset {
+			fmt.Fprintf(&b, "/%d'", index-HardenedOffset)
+		} else {
+			fmt.Fprintf(&b, "/%d", index)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// ExtendedKeyLength is the length of a serialized BIP32 extended key, without
+// the Base58Check checksum.
+const ExtendedKeyLength = 78
+
+// ExtendedKey is a BIP32 extended private or public key.
+type ExtendedKey struct {
+	Version           uint32
+	Depth             uint8
+	ParentFingerprint [4]byte
+	ChildNumber       uint32
+	ChainCode         [32]byte
+	// Key is 0x00 followed by the 32-byte private key for private keys, or the
+	// compressed public key for public keys
+	Key [33]byte
+}
+
+// ParseExtendedKey parses a Base58Check encoded extended key, like an xprv or
+// tpub string.
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	data, err := Base58CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeExtendedKey(data)
+}
+
+// DecodeExtendedKey decodes the 78-byte serialization of an extended key. It
+// validates the version bytes, the key material and the depth fields.
+func DecodeExtendedKey(data []byte) (*ExtendedKey, error) {
+	if len(data) != ExtendedKeyLength {
+		return nil, fmt.Errorf("invalid extended key length: %d", len(data))
+	}
+
+	key := &ExtendedKey{
+		Version:     binary.BigEndian.Uint32(data[0:4]),
+		Depth:       data[4],
+		ChildNumber: binary.BigEndian.Uint32(data[9:13]),
+	}
+	copy(key.ParentFingerprint[:], data[5:9])
+	copy(key.ChainCode[:], data[13:45])
+	copy(key.Key[:], data[45:78])
+
+	if key.Network() == nil {
+		return nil, fmt.Errorf("unknown extended key version: %08x", key.Version)
+	}
+	if key.Depth == 0 && (key.ParentFingerprint != [4]byte{} || key.ChildNumber != 0) {
+		return nil, fmt.Errorf("master key with non-zero parent fingerprint or child number")
+	}
+
+	if key.IsPrivate() {
+		if key.Key[0] != 0x00 {
+			return nil, fmt.Errorf("invalid private key prefix: %02x", key.Key[0])
+		}
+		if err := validatePrivateKey(key.Key[1:]); err != nil {
+			return nil, err
+		}
+	} else if _, err := secp256k1.ParsePubKey(key.Key[:]); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return key, nil
+}
+
+// Serialize returns the 78-byte serialization of the key.
+func (k *ExtendedKey) Serialize() []byte {
+	data := make([]byte, ExtendedKeyLength)
+	binary.BigEndian.PutUint32(data[0:4], k.Version)
+	data[4] = k.Depth
+	copy(data[5:9], k.ParentFingerprint[:])
+	binary.BigEndian.PutUint32(data[9:13], k.ChildNumber)
+	copy(data[13:45], k.ChainCode[:])
+	copy(data[45:78], k.Key[:])
+	return data
+}
+
+// String returns the Base58Check encoding of the key.
+func (k *ExtendedKey) String() string {
+	return Base58CheckEncode(k.Serialize())
+}
+
+// Network returns the network the key version belongs to, or nil if the
+// version is unknown.
+func (k *ExtendedKey) Network() *Network {
+	for _, net := range Networks {
+		if k.Version == net.PrivateVersion || k.Version == net.PublicVersion {
+			return net
+		}
+	}
+	return nil
+}
+
+// IsPrivate reports whether the key is an extended private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	net := k.Network()
+	return net != nil && k.Version == net.PrivateVersion
+}
+
+// PublicKey returns the compressed public key corresponding to the key.
+func (k *ExtendedKey) PublicKey() []byte {
+	if !k.IsPrivate() {
+		return k.Key[:]
+	}
+	return publicKey(k.Key[1:], true)
+}
+
+// Neuter returns the extended public key corresponding to the key.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.IsPrivate() {
+		return k
+	}
+	pub := *k
+	pub.Version = k.Network().PublicVersion
+	copy(pub.Key[:], k.PublicKey())
+	return &pub
+}
+
+// Fingerprint returns the BIP32 fingerprint of the key: the first 4 bytes of
+// the HASH160 of its public key.
+func (k *ExtendedKey) Fingerprint() [4]byte {
+	var fp [4]byte
+	copy(fp[:], Hash160(k.PublicKey()))
+	return fp
+}
+
+// Hash160 returns RIPEMD160(SHA256(data)).
+func Hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+// validatePrivateKey checks that key is a valid secp256k1 private key, that
+// is a 32-byte integer in the range [1, n-1].
+func validatePrivateKey(key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("invalid private key length: %d", len(key))
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(key); overflow || scalar.IsZero() {
+		return fmt.Errorf("private key out of range")
+	}
+	return nil
+}
+
+// publicKey returns the serialized public key for a valid private key.
+func publicKey(privateKey []byte, compressed bool) []byte {
+	priv := secp256k1.PrivKeyFromBytes(privateKey)
+	defer priv.Zero()
+	if compressed {
+		return priv.PubKey().SerializeCompressed()
+	}
+	return priv.PubKey().SerializeUncompressed()
+}
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase58Check(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		encoded string
+	}{
+		{
+			name:    "p2pkh_address",
+			data:    "00010966776006953d5567439e5e39f86a0d273bee",
+			encoded: "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM",
+		},
+		{
+			name:    "leading_zeros",
+			data:    "000000",
+			encoded: "11146EAsf",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.encoded, Base58CheckEncode(data))
+
+			decoded, err := Base58CheckDecode(tc.encoded)
+			require.NoError(t, err)
+			assert.Equal(t, data, decoded)
+		})
+	}
+
+	t.Run("invalid_checksum", func(t *testing.T) {
+		_, err := Base58CheckDecode("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid base58check checksum")
+	})
+
+	t.Run("invalid_character", func(t *testing.T) {
+		_, err := Base58Decode("16UwLL0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid base58 character")
+	})
+}
+
+func TestExtendedKey(t *testing.T) {
+	// BIP32 test vector 1
+	testCases := []struct {
+		name string
+		xprv string
+		xpub string
+	}{
+		{
+			name: "master",
+			xprv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+			xpub: "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		},
+		{
+			name: "m/0H",
+			xprv: "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+			xpub: "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := ParseExtendedKey(tc.xprv)
+			require.NoError(t, err)
+			assert.True(t, key.IsPrivate())
+			assert.Equal(t, MainNet, key.Network())
+			assert.Equal(t, tc.xprv, key.String())
+			assert.Equal(t, tc.xpub, key.Neuter().String())
+
+			decoded, err := DecodeExtendedKey(key.Serialize())
+			require.NoError(t, err)
+			assert.Equal(t, key, decoded)
+		})
+	}
+
+	t.Run("unknown_version", func(t *testing.T) {
+		key, err := ParseExtendedKey(testCases[0].xprv)
+		require.NoError(t, err)
+		key.Version = 0x01020304
+
+		_, err = DecodeExtendedKey(key.Serialize())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown extended key version")
+	})
+
+	t.Run("invalid_private_key", func(t *testing.T) {
+		key, err := ParseExtendedKey(testCases[0].xprv)
+		require.NoError(t, err)
+		key.Key = [33]byte{}
+
+		_, err = DecodeExtendedKey(key.Serialize())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "private key out of range")
+	})
+}
+
+func TestWIF(t *testing.T) {
+	testCases := []struct {
+		name       string
+		wif        string
+		compressed bool
+		address    string
+	}{
+		{
+			name:    "uncompressed",
+			wif:     "5J1F7GHadZG3sCCKHCwg8Jvys9xUbFsjLnGec4H125Ny1V9nR6V",
+			address: "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM",
+		},
+		{
+			name:       "compressed",
+			wif:        "Kx45GeUBSMPReYQwgXiKhG9FzNXrnCeutJp4yjTd5kKxCitadm3C",
+			compressed: true,
+			address:    "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wif, err := ParseWIF(tc.wif)
+			require.NoError(t, err)
+			assert.Equal(t, MainNet, wif.Network)
+			assert.Equal(t, tc.compressed, wif.Compressed)
+			assert.Equal(t, "18e14a7b6a307f426a94f8114701e7c8e774e7f9a47e2c2035db29a206321725", hex.EncodeToString(wif.PrivateKey[:]))
+			assert.Equal(t, tc.wif, wif.String())
+			assert.Equal(t, tc.address, wif.Address())
+		})
+	}
+
+	t.Run("unknown_version", func(t *testing.T) {
+		data := append([]byte{0x42}, make([]byte, 32)...)
+		data[32] = 0x01
+
+		_, err := DecodeWIF(data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown WIF version")
+	})
+}
@@ -0,0 +1,58 @@
+package wallet
+
+// Network holds the version bytes used to serialize keys and addresses for a
+// Bitcoin network.
+type Network struct {
+	// Name is a human-readable name for the network
+	Name string
+	// PrivateVersion and PublicVersion are the BIP32 extended key versions
+	// (xprv/xpub on mainnet, tprv/tpub on testnet)
+	PrivateVersion, PublicVersion uint32
+	// WIFVersion is the version byte of Wallet Import Format private keys
+	WIFVersion byte
+	// PubKeyHashVersion and ScriptHashVersion are the version bytes of P2PKH
+	// and P2SH addresses
+	PubKeyHashVersion, ScriptHashVersion byte
+	// Bech32HRP is the human-readable part of native SegWit addresses
+	Bech32HRP string
+	// CoinType is the BIP44 coin type used in account derivation paths
+	CoinType uint32
+	// NestedSegWitPublicVersion and SegWitPublicVersion are the SLIP-132
+	// extended public key versions of BIP49 and BIP84 accounts (ypub/zpub on
+	// mainnet, upub/vpub on testnet)
+	NestedSegWitPublicVersion, SegWitPublicVersion uint32
+}
+
+var (
+	// MainNet holds the Bitcoin mainnet version bytes.
+	MainNet = &Network{
+		Name:              "mainnet",
+		PrivateVersion:    0x0488ade4,
+		PublicVersion:     0x0488b21e,
+		WIFVersion:        0x80,
+		PubKeyHashVersion: 0x00,
+		ScriptHashVersion: 0x05,
+		Bech32HRP:         "bc",
+		CoinType:          0,
+
+		NestedSegWitPublicVersion: 0x049d7cb2,
+		SegWitPublicVersion:       0x04b24746,
+	}
+	// TestNet holds the Bitcoin testnet (and signet/regtest) version bytes.
+	TestNet = &Network{
+		Name:              "testnet",
+		PrivateVersion:    0x04358394,
+		PublicVersion:     0x043587cf,
+		WIFVersion:        0xef,
+		PubKeyHashVersion: 0x6f,
+		ScriptHashVersion: 0xc4,
+		Bech32HRP:         "tb",
+		CoinType:          1,
+
+		NestedSegWitPublicVersion: 0x044a5262,
+		SegWitPublicVersion:       0x045f1cf6,
+	}
+)
+
+// Networks lists all networks known to this package.
+var Networks = []*Network{MainNet, TestNet}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"fmt"
+)
+
+// WIF is a private key in Wallet Import Format.
+type WIF struct {
+	Network    *Network
+	PrivateKey [32]byte
+	// Compressed indicates that the key is used with a compressed public key
+	Compressed bool
+}
+
+// ParseWIF parses a Base58Check encoded WIF private key.
+func ParseWIF(s string) (*WIF, error) {
+	data, err := Base58CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWIF(data)
+}
+
+// DecodeWIF decodes the WIF payload: a version byte, the 32-byte private key
+// and, for compressed keys, a trailing 0x01 byte.
+func DecodeWIF(data []byte) (*WIF, error) {
+	wif := &WIF{}
+	switch {
+	case len(data) == 34 && data[33] == 0x01:
+		wif.Compressed = true
+	case len(data) != 33:
+		return nil, fmt.Errorf("invalid WIF length: %d", len(data))
+	}
+
+	for _, net := range Networks {
+		if data[0] == net.WIFVersion {
+			wif.Network = net
+		}
+	}
+	if wif.Network == nil {
+		return nil, fmt.Errorf("unknown WIF version: %02x", data[0])
+	}
+
+	if err := validatePrivateKey(data[1:33]); err != nil {
+		return nil, err
+	}
+	copy(wif.PrivateKey[:], data[1:33])
+	return wif, nil
+}
+
+// Serialize returns the WIF payload, without the Base58Check checksum.
+func (w *WIF) Serialize() []byte {
+	data := append([]byte{w.Network.WIFVersion}, w.PrivateKey[:]...)
+	if w.Compressed {
+		data = append(data, 0x01)
+	}
+	return data
+}
+
+// String returns the Base58Check encoding of the key.
+func (w *WIF) String() string {
+	return Base58CheckEncode(w.Serialize())
+}
+
+// PublicKey returns the public key of the WIF, serialized in the compressed or
+// uncompressed form according to the WIF.
+func (w *WIF) PublicKey() []byte {
+	return publicKey(w.PrivateKey[:], w.Compressed)
+}
+
+// Address returns the legacy P2PKH address of the key.
+func (w *WIF) Address() string {
+	return P2PKHAddress(w.PublicKey(), w.Network)
+}
+
+// P2PKHAddress returns the legacy pay-to-public-key-hash address for the
+// serialized public key.
+func P2PKHAddress(pubKey []byte, net *Network) string {
+	return Base58CheckEncode(append([]byte{net.PubKeyHashVersion}, Hash160(pubKey)...))
+}
@@ -0,0 +1,199 @@
+// Package wordcode converts BIP39 words to and from the short forms used on
+// metal backups: 4-letter prefixes, decimal word indices, and 11-bit binary or
+// punched-dot patterns.
+package wordcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/model"
+)
+
+// Format is a way of writing the words of a mnemonic.
+type Format string
+
+const (
+	// Words writes the full words.
+	Words Format = "words"
+	// Prefix writes the first 4 letters of each word, which are unique in
+	// the BIP39 English wordlist.
+	Prefix Format = "prefix"
+	// Index writes the decimal index of each word in the wordlist.
+	Index Format = "index"
+	// Binary writes the 11-bit binary form of the 0-based word index.
+	Binary Format = "binary"
+	// Dots writes the 11-bit binary form of the 0-based word index as a row
+	// of punched (●) and empty (○) dots.
+	Dots Format = "dots"
+)
+
+const (
+	// PrefixLength is the number of letters that identify a BIP39 word.
+	PrefixLength = 4
+	// bitsPerWord is the number of bits encoded by each word.
+	bitsPerWord = 11
+	// wordCount is the number of words in the wordlist.
+	wordCount = 1 << bitsPerWord
+
+	punchedDot = '●'
+	emptyDot   = '○'
+)
+
+// ParseFormat parses a format name like "prefix" or "dots".
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(name)))
+	switch format {
+	case Words, Prefix, Index, Binary, Dots:
+		return format, nil
+	case "":
+		return Words, nil
+	}
+	return "", fmt.Errorf("unknown word format: %s (expected words, prefix, index, binary or dots)", name)
+}
+
+// Expand returns the full BIP39 word written as token, which may be the word
+// itself, a unique prefix of at least 4 letters, a decimal word index, an
+// 11-bit binary pattern or a row of 11 dots. Decimal indices start at base,
+// which must be 0 or 1, while binary and dot patterns always encode the
+// 0-based index.
+func Expand(token string, base int) (string, error) {
+	if base != 0 && base != 1 {
+		return "", fmt.Errorf("invalid index base: %d", base)
+	}
+	token = strings.ToLower(strings.TrimSpace(token))
+	if token == "" {
+		return "", fmt.Errorf("empty word")
+	}
+
+	if bits, ok := dotsToBinary(token); ok {
+		token = bits
+	}
+	if len(token) == bitsPerWord && strings.Trim(token, "01") == "" {
+		index, _ := strconv.ParseUint(token, 2, bitsPerWord)
+		return bip39.GetWordList()[index], nil
+	}
+	if strings.Trim(token, "0123456789") == "" {
+		index, err := strconv.Atoi(token)
+		if err != nil || index < base || index >= wordCount+base {
+			return "", fmt.Errorf("invalid word index: %s (expected %d to %d)", token, base, wordCount-1+base)
+		}
+		return bip39.GetWordList()[index-base], nil
+	}
+	return expandPrefix(token)
+}
+
+// ExpandPhrase expands every word of phrase with Expand and returns the full
+// words separated by single spaces.
+func ExpandPhrase(phrase string, base int) (string, error) {
+	tokens := strings.Fields(phrase)
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		word, err := Expand(token, base)
+		var invalid *model.ErrInvalidWord
+		if errors.As(err, &invalid) {
+			invalid.Position = i + 1
+			return "", invalid
+		} else if err != nil {
+			return "", fmt.Errorf("word %d: %w", i+1, err)
+		}
+		words[i] = word
+	}
+	return strings.Join(words, " "), nil
+}
+
+// Encode writes a full BIP39 word in the given format. Decimal indices start at
+// base, which must be 0 or 1.
+func Encode(word string, format Format, base int) (string, error) {
+	if base != 0 && base != 1 {
+		return "", fmt.Errorf("invalid index base: %d", base)
+	}
+	index, ok := bip39.GetWordIndex(word)
+	if !ok {
+		return "", fmt.Errorf("invalid word: %s", word)
+	}
+
+	switch format {
+	case Words, "":
+		return word, nil
+	case Prefix:
+		return word[:min(PrefixLength, len(word))], nil
+	case Index:
+		return strconv.Itoa(index + base), nil
+	case Binary:
+		return fmt.Sprintf("%0*b", bitsPerWord, index), nil
+	case Dots:
+		bits := fmt.Sprintf("%0*b", bitsPerWord, index)
+		return strings.Map(func(r rune) rune {
+			if r == '1' {
+				return punchedDot
+			}
+			return emptyDot
+		}, bits), nil
+	}
+	return "", fmt.Errorf("unknown word format: %s", format)
+}
+
+// EncodePhrase writes every word of a mnemonic in the given format, separated
+// by single spaces.
+func EncodePhrase(mnemonic string, format Format, base int) (string, error) {
+	words := strings.Fields(mnemonic)
+	codes := make([]string, len(words))
+	for i, word := range words {
+		code, err := Encode(word, format, base)
+		if err != nil {
+			return "", err
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, " "), nil
+}
+
+// expandPrefix returns the word that is equal to token or, if token has at
+// least 4 letters, the only word that starts with it.
+func expandPrefix(token string) (string, error) {
+	// the entry of the wordlist is returned instead of token, so the word is
+	// not kept in a string typed by the user
+	list := bip39.GetWordList()
+	if index, ok := bip39.GetWordIndex(token); ok {
+		return list[index], nil
+	}
+	if len(token) < PrefixLength {
+		return "", &model.ErrInvalidWord{Word: token}
+	}
+
+	// the English wordlist is sorted, so the matches are consecutive
+	start := sort.SearchStrings(list, token)
+	end := start
+	for end < len(list) && strings.HasPrefix(list[end], token) {
+		end++
+	}
+	switch end - start {
+	case 0:
+		return "", &model.ErrInvalidWord{Word: token}
+	case 1:
+		return list[start], nil
+	}
+	return "", fmt.Errorf("ambiguous word prefix: %s", token)
+}
+
+// dotsToBinary converts a row of 11 dots to a binary pattern. Punched dots may
+// be written as ● or *, and empty ones as ○ or a period.
+func dotsToBinary(token string) (string, bool) {
+	var bits strings.Builder
+	for _, r := range token {
+		switch r {
+		case punchedDot, '*':
+			bits.WriteByte('1')
+		case emptyDot, '.':
+			bits.WriteByte('0')
+		default:
+			return "", false
+		}
+	}
+	return bits.String(), bits.Len() == bitsPerWord
+}
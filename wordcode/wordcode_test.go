@@ -0,0 +1,106 @@
+package wordcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/victorges/recovery-shards/model"
+)
+
+func TestExpand(t *testing.T) {
+	testCases := []struct {
+		name, token string
+		base        int
+		expected    string
+	}{
+		{name: "full_word", token: "abandon", base: 1, expected: "abandon"},
+		{name: "short_word", token: "zoo", base: 1, expected: "zoo"},
+		{name: "prefix", token: "aban", base: 1, expected: "abandon"},
+		{name: "longer_prefix", token: "aband", base: 1, expected: "abandon"},
+		{name: "upper_case_prefix", token: "ZOO", base: 1, expected: "zoo"},
+		{name: "index_1_based", token: "1", base: 1, expected: "abandon"},
+		{name: "index_1_based_last", token: "2048", base: 1, expected: "zoo"},
+		{name: "index_0_based", token: "0", base: 0, expected: "abandon"},
+		{name: "index_leading_zeros", token: "0004", base: 1, expected: "about"},
+		{name: "binary", token: "00000000011", base: 1, expected: "about"},
+		{name: "binary_last", token: "11111111111", base: 0, expected: "zoo"},
+		{name: "dots", token: "○○○○○○○○○●●", base: 1, expected: "about"},
+		{name: "ascii_dots", token: ".........**", base: 1, expected: "about"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			word, err := Expand(tc.token, tc.base)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, word)
+		})
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	testCases := []struct {
+		name, token string
+		base        int
+		errMsg      string
+	}{
+		{name: "unknown_word", token: "bitcoin", base: 1, errMsg: "invalid word: bitcoin"},
+		{name: "short_prefix", token: "aba", base: 1, errMsg: "invalid word: aba"},
+		{name: "index_zero_1_based", token: "0", base: 1, errMsg: "invalid word index: 0 (expected 1 to 2048)"},
+		{name: "index_too_high", token: "2048", base: 0, errMsg: "invalid word index: 2048 (expected 0 to 2047)"},
+		{name: "short_dots", token: "●●○", base: 1, errMsg: "invalid word: ●●○"},
+		{name: "invalid_base", token: "1", base: 2, errMsg: "invalid index base: 2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Expand(tc.token, tc.base)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+
+	t.Run("phrase_position", func(t *testing.T) {
+		_, err := ExpandPhrase("aban abil bitcoin", 1)
+		var invalid *model.ErrInvalidWord
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, model.ErrInvalidWord{Position: 3, Word: "bitcoin"}, *invalid)
+		assert.EqualError(t, err, "invalid word 3: bitcoin")
+	})
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	entropy, err := bip39.NewEntropy(256)
+	require.NoError(t, err)
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	require.NoError(t, err)
+
+	for _, format := range []Format{Words, Prefix, Index, Binary, Dots} {
+		for _, base := range []int{0, 1} {
+			encoded, err := EncodePhrase(mnemonic, format, base)
+			require.NoError(t, err)
+			decoded, err := ExpandPhrase(encoded, base)
+			require.NoError(t, err)
+			assert.Equal(t, mnemonic, decoded, "format %s, base %d", format, base)
+		}
+	}
+
+	code, err := Encode("zoo", Index, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2048", code)
+	code, err = Encode("about", Dots, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "○○○○○○○○○●●", code)
+	code, err = Encode("abandon", Prefix, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "aban", code)
+}
+
+func TestUniquePrefixes(t *testing.T) {
+	for _, word := range bip39.GetWordList() {
+		expanded, err := Expand(word[:min(PrefixLength, len(word))], 1)
+		require.NoError(t, err)
+		require.Equal(t, word, expanded)
+	}
+}